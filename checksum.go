@@ -0,0 +1,83 @@
+package shellcligen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// generatedMarker starts the checksum recorded in the header of generated files.
+const generatedMarker = "generated by shellcligen, sha256:"
+
+var (
+	ErrModifiedOutput = errors.New("error modified output file")
+
+	generatedHeaderRegex = regexp.MustCompile(`generated by shellcligen, sha256:([0-9a-f]{64})`)
+)
+
+// headerCommentFormat returns how a comment is written in the generated file.
+func headerCommentFormat(fileName string) string {
+	switch path.Ext(fileName) {
+	case ".md":
+		return "<!-- %s -->\n"
+	case ".1":
+		return ".\\\" %s\n"
+	default:
+		return "# %s\n"
+	}
+}
+
+// contentChecksum returns the checksum of content without its header and the code of its user code
+// regions, which can be edited.
+func contentChecksum(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+
+	if regions, err := findUserCodeRegions(lines); err == nil {
+		for i := len(regions) - 1; i >= 0; i-- {
+			lines = append(lines[:regions[i].begin+1], lines[regions[i].end:]...)
+		}
+	}
+
+	hash := sha256.New()
+
+	for _, line := range lines {
+		if !generatedHeaderRegex.MatchString(line) {
+			hash.Write([]byte(line))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// withGeneratedHeader returns content with a header recording its checksum, after the shebang or
+// the #compdef line if it starts with one.
+func withGeneratedHeader(fileName, content string) string {
+	header := fmt.Sprintf(headerCommentFormat(fileName), generatedMarker+contentChecksum(content))
+
+	if strings.HasPrefix(content, "#!") || strings.HasPrefix(content, "#compdef") {
+		if i := strings.Index(content, "\n"); i != -1 {
+			return content[:i+1] + header + content[i+1:]
+		}
+	}
+
+	return header + content
+}
+
+// checkUnmodified returns an error when content has no header or no longer matches the checksum of
+// its header.
+func checkUnmodified(content string) error {
+	match := generatedHeaderRegex.FindStringSubmatch(content)
+	if match == nil {
+		return fmt.Errorf("no checksum found, it was not generated or its header was edited: %w", ErrModifiedOutput)
+	}
+
+	if match[1] != contentChecksum(content) {
+		return fmt.Errorf("changed since it was generated: %w", ErrModifiedOutput)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package shellcligen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_withGeneratedHeader(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		fileName string
+		content  string
+		want     string
+	}
+
+	tests := []test{
+		{fileName: "script.sh", content: "#!/bin/bash\necho\n", want: "#!/bin/bash\n# generated by shellcligen, sha256:"},
+		{fileName: "_script.sh", content: "#compdef script.sh\n", want: "#compdef script.sh\n# generated by shellcligen, sha256:"},
+		{fileName: "script.conf", content: "# Configuration\n", want: "# generated by shellcligen, sha256:"},
+		{fileName: "script.md", content: "# script.sh\n", want: "<!-- generated by shellcligen, sha256:"},
+		{fileName: "script.sh.1", content: ".TH SCRIPT.SH 1\n", want: ".\\\" generated by shellcligen, sha256:"},
+	}
+
+	for _, tt := range tests {
+		got := withGeneratedHeader(tt.fileName, tt.content)
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("got=[%s], want=[%s...]", got, tt.want)
+		}
+
+		lines := strings.SplitAfter(got, "\n")
+		if header := strings.Count(tt.want, "\n"); strings.Join(append(lines[:header], lines[header+1:]...), "") != tt.content {
+			t.Errorf("got=[%s], want the content of %s kept", got, tt.fileName)
+		}
+
+		if err := checkUnmodified(got); err != nil {
+			t.Errorf("got=%v, want %s unmodified", err, tt.fileName)
+		}
+	}
+}
+
+func Test_checkUnmodified(t *testing.T) {
+	t.Parallel()
+
+	script := withGeneratedHeader(scriptFileName, "#!/bin/bash\nparse\n# BEGIN USER CODE main\n# END USER CODE\n")
+
+	type test struct {
+		content string
+		err     error
+	}
+
+	tests := []test{
+		{content: script},
+		{content: strings.Replace(script, "# BEGIN USER CODE main\n", "# BEGIN USER CODE main\nrun \"${@}\"\n", 1)},
+		{content: strings.Replace(script, "parse\n", "parse --verbose\n", 1), err: ErrModifiedOutput},
+		{content: strings.Replace(script, "# END USER CODE\n", "", 1), err: ErrModifiedOutput},
+		{content: "#!/bin/bash\nparse\n", err: ErrModifiedOutput},
+	}
+
+	for _, tt := range tests {
+		if err := checkUnmodified(tt.content); !errors.Is(err, tt.err) {
+			t.Errorf("got=%v, want=%v checking [%s]", err, tt.err, tt.content)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"text/template"
+
+	"github.com/leogtzr/shellcligen"
+)
+
+// loadProgram loads a spec and validates it.
+func loadProgram(fileName string, out *output) (shellcligen.CLIProgram, error) {
+	cli, err := shellcligen.LoadFile(fileName)
+	if err != nil {
+		return shellcligen.CLIProgram{}, err
+	}
+
+	out.debug("loaded %s: %d options, %d positionals, %d commands",
+		fileName, len(cli.Options), len(cli.Positionals), len(cli.Commands))
+
+	if err := cli.Validate(); err != nil {
+		return shellcligen.CLIProgram{}, err
+	}
+
+	return cli, nil
+}
+
+// overrideName names the program after the -name flag, when given, and checks the name.
+func overrideName(cli *shellcligen.CLIProgram, name string) error {
+	if len(name) == 0 {
+		return nil
+	}
+
+	if err := (&shellcligen.CLIProgram{Name: name}).Validate(); err != nil {
+		return fmt.Errorf("-name: %w", err)
+	}
+
+	cli.Name = name
+
+	return nil
+}
+
+// writeOutput writes content to fileName, or to stdout when no file name is given.
+func writeOutput(fileName string, content []byte, out *output) error {
+	if len(fileName) == 0 {
+		_, err := os.Stdout.Write(content)
+
+		return err
+	}
+
+	if err := os.WriteFile(fileName, content, 0o644); err != nil {
+		return err
+	}
+
+	out.info("wrote %s", fileName)
+
+	return nil
+}
+
+// loadScriptTemplate returns the template the script is rendered with: the one of templateFile, or
+// the built-in one, with the blocks of the partials of partialsDirectory overridden. nil is returned
+// when neither is given, the generator then using the built-in template.
+func loadScriptTemplate(templateFile, partialsDirectory string, out *output) (*template.Template, error) {
+	if len(templateFile) == 0 && len(partialsDirectory) == 0 {
+		return nil, nil
+	}
+
+	text := shellcligen.BuiltinScriptTemplate()
+
+	if len(templateFile) > 0 {
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template: %w", err)
+		}
+
+		text = string(content)
+		out.debug("using template %s", templateFile)
+	}
+
+	scriptTemplate, err := shellcligen.ParseScriptTemplate(text)
+	if err != nil || len(partialsDirectory) == 0 {
+		return scriptTemplate, err
+	}
+
+	partials, err := shellcligen.LoadScriptPartials(partialsDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("error reading partials: %w", err)
+	}
+
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		out.debug("overriding block %s with %s", name, path.Join(partialsDirectory, name+".tmpl"))
+	}
+
+	return shellcligen.OverrideScriptBlocks(scriptTemplate, partials)
+}
+
+func runGenerate(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	outputDirectory := flags.String("output", ".", "directory the files are generated in")
+	makeDirectory := flags.Bool("mkdir", false, "create the output directory if it does not exist")
+	name := flags.String("name", "", "file name of the script, overriding the name of the spec")
+	inputFile := flags.String("input", "", "spec to generate from, the same as giving it as argument")
+	templateFile := flags.String("template", "", "text/template the script is rendered with instead of the built-in one")
+	partialsDirectory := flags.String("partials", "", "directory of partials overriding blocks of the template, like header.tmpl")
+	force := flags.Bool("force", false, "overwrite files changed since they were generated")
+	printTemplate := flags.Bool("print-template", false, "print the built-in script template, to start a custom one from, and exit")
+
+	if err := parseFlags(flags, out, args, 0, 1); err != nil {
+		return err
+	}
+
+	if *printTemplate {
+		_, err := fmt.Print(shellcligen.BuiltinScriptTemplate())
+
+		return err
+	}
+
+	scriptTemplate, err := loadScriptTemplate(*templateFile, *partialsDirectory, out)
+	if err != nil {
+		return err
+	}
+
+	specFile := *inputFile
+	if flags.NArg() == 1 {
+		specFile = flags.Arg(0)
+	}
+
+	if len(specFile) == 0 {
+		return fmt.Errorf("missing spec: %w", errUsage)
+	}
+
+	cli, err := loadProgram(specFile, out)
+	if err != nil {
+		return err
+	}
+
+	if err := overrideName(&cli, *name); err != nil {
+		return err
+	}
+
+	generator := shellcligen.Generator{ScriptTemplate: scriptTemplate, Force: *force, MakeDirectory: *makeDirectory}
+
+	files, err := generator.GenerateDir(&cli, *outputDirectory)
+	for _, file := range files {
+		out.debug("wrote %s (%d bytes)", path.Join(*outputDirectory, file.Name), len(file.Content))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	out.info("generated %d files from %s in %s", len(files), specFile, *outputDirectory)
+
+	return nil
+}
+
+func runValidate(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+
+	if err := parseFlags(flags, out, args, 1, -1); err != nil {
+		return err
+	}
+
+	failed := false
+
+	for _, specFile := range flags.Args() {
+		if _, err := loadProgram(specFile, out); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+
+			failed = true
+
+			continue
+		}
+
+		out.info("%s: ok", specFile)
+	}
+
+	if failed {
+		return errFailed
+	}
+
+	return nil
+}
+
+func runFmt(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	write := flags.Bool("w", false, "write the result to the spec instead of stdout")
+	list := flags.Bool("l", false, "list the specs whose layout differs from the canonical one")
+
+	if err := parseFlags(flags, out, args, 1, -1); err != nil {
+		return err
+	}
+
+	for _, specFile := range flags.Args() {
+		content, err := os.ReadFile(specFile)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %w", shellcligen.ErrReadingInputFile)
+		}
+
+		cli, err := shellcligen.LoadFile(specFile)
+		if err != nil {
+			return err
+		}
+
+		format := shellcligen.DetectFormat(specFile, content)
+
+		formatted, err := shellcligen.Marshal(&cli, format)
+		if err != nil {
+			return err
+		}
+
+		changed := !bytes.Equal(content, formatted)
+		out.debug("%s: %s, changed: %t", specFile, format, changed)
+
+		switch {
+		case *list:
+			if changed {
+				fmt.Println(specFile)
+			}
+		case *write:
+			if !changed {
+				continue
+			}
+
+			if err := os.WriteFile(specFile, formatted, 0o644); err != nil {
+				return err
+			}
+
+			out.info("formatted %s", specFile)
+		default:
+			if _, err := os.Stdout.Write(formatted); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func runDocs(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	format := flags.String("format", "markdown", "documentation format: markdown or man")
+	outputFile := flags.String("output", "", "file the documentation is written to, stdout by default")
+	name := flags.String("name", "", "file name of the script, overriding the name of the spec")
+
+	if err := parseFlags(flags, out, args, 1, 1); err != nil {
+		return err
+	}
+
+	generateDocs := shellcligen.GenerateMarkdown
+
+	switch *format {
+	case "markdown":
+	case "man":
+		generateDocs = shellcligen.GenerateManPage
+	default:
+		return fmt.Errorf("unknown documentation format %q: %w", *format, errUsage)
+	}
+
+	cli, err := loadProgram(flags.Arg(0), out)
+	if err != nil {
+		return err
+	}
+
+	if err := overrideName(&cli, *name); err != nil {
+		return err
+	}
+
+	return generateOutput(*outputFile, out, func(w io.Writer) error {
+		return generateDocs(&cli, w)
+	})
+}
+
+func runCompletion(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	shell := flags.String("shell", shellcligen.ShellBash, "shell to complete in: bash, zsh or fish")
+	outputFile := flags.String("output", "", "file the completion script is written to, stdout by default")
+	name := flags.String("name", "", "file name of the script, overriding the name of the spec")
+
+	if err := parseFlags(flags, out, args, 1, 1); err != nil {
+		return err
+	}
+
+	switch *shell {
+	case shellcligen.ShellBash, shellcligen.ShellZsh, shellcligen.ShellFish:
+	default:
+		return fmt.Errorf("unknown shell %q: %w", *shell, errUsage)
+	}
+
+	cli, err := loadProgram(flags.Arg(0), out)
+	if err != nil {
+		return err
+	}
+
+	if err := overrideName(&cli, *name); err != nil {
+		return err
+	}
+
+	return generateOutput(*outputFile, out, func(w io.Writer) error {
+		return shellcligen.GenerateCompletion(&cli, *shell, w)
+	})
+}
+
+// generateOutput writes what generate writes to outputFile, or to stdout when no file is given.
+func generateOutput(outputFile string, out *output, generate func(w io.Writer) error) error {
+	var buffer bytes.Buffer
+
+	if err := generate(&buffer); err != nil {
+		return err
+	}
+
+	return writeOutput(outputFile, buffer.Bytes(), out)
+}
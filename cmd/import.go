@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leogtzr/shellcligen"
+)
+
+// runImport imports a program from a script parsing its options with getopt or from the help
+// output of a tool. The input is read from stdin when no file is given and the program is written
+// to stdout when no output is given.
+func runImport(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	outputFile := flags.String("output", "", "file where the imported program will be written")
+	from := flags.String("from", "getopt", "what the input is: getopt (a script calling getopt) or help (a --help output)")
+
+	if err := parseFlags(flags, out, args, 0, 1); err != nil {
+		return err
+	}
+
+	importProgram := shellcligen.ImportGetopt
+
+	switch *from {
+	case "getopt":
+	case "help":
+		importProgram = shellcligen.ImportHelp
+	default:
+		return fmt.Errorf("unknown import source %q: %w", *from, errUsage)
+	}
+
+	var input io.Reader = os.Stdin
+
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error opening input file: %w", shellcligen.ErrOpeningInputFile)
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	script, err := io.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("error reading input file: %w", shellcligen.ErrReadingInputFile)
+	}
+
+	cli, warnings, err := importProgram(string(script))
+
+	for _, warning := range warnings {
+		out.warn("%s", warning)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	out.debug("imported %d options", len(cli.Options))
+
+	content, err := shellcligen.MarshalCLIProgram(&cli)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*outputFile, content, out)
+}
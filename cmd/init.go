@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leogtzr/shellcligen"
+)
+
+var errInvalidOptionFlag = errors.New("invalid option")
+
+// optionsFlag holds the options given with -option.
+type optionsFlag []shellcligen.CLIOption
+
+func (options *optionsFlag) String() string {
+	return fmt.Sprintf("%d options", len(*options))
+}
+
+// Set parses an option given as comma separated fields, like
+// `short=a,long=article,required,arg,conflicts=p|q,description=text`. The description takes the
+// rest of the value so it can hold commas.
+func (options *optionsFlag) Set(value string) error {
+	cliOption := shellcligen.CLIOption{}
+
+	for len(value) > 0 {
+		field := value
+
+		if strings.HasPrefix(value, "description=") {
+			value = ""
+		} else if i := strings.Index(value, ","); i != -1 {
+			field, value = value[:i], value[i+1:]
+		} else {
+			value = ""
+		}
+
+		key, fieldValue := field, ""
+		if i := strings.Index(field, "="); i != -1 {
+			key, fieldValue = field[:i], field[i+1:]
+		}
+
+		switch key {
+		case "short":
+			cliOption.ShortName = fieldValue
+		case "long":
+			cliOption.LongName = fieldValue
+		case "required":
+			cliOption.Required = true
+		case "arg":
+			cliOption.ArgsRequired = true
+		case "help":
+			cliOption.Help = true
+		case "conflicts":
+			cliOption.ConflictsWith = strings.Split(fieldValue, "|")
+		case "description":
+			cliOption.Description = fieldValue
+		default:
+			return fmt.Errorf("unknown field %q: %w", key, errInvalidOptionFlag)
+		}
+	}
+
+	*options = append(*options, cliOption)
+
+	return nil
+}
+
+// prompter asks questions in the terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints the question and reads the answer until check, if any, accepts it. io.EOF is returned
+// when the input ends before an answer is given.
+func (p *prompter) ask(question string, check func(answer string) error) (string, error) {
+	for {
+		fmt.Fprintf(p.out, "%s: ", question)
+
+		line, err := p.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+			fmt.Fprintln(p.out)
+
+			return "", err
+		}
+
+		answer := strings.TrimSpace(line)
+		if check == nil {
+			return answer, nil
+		}
+
+		checkErr := check(answer)
+		if checkErr == nil {
+			return answer, nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			return "", checkErr
+		}
+
+		fmt.Fprintf(p.out, "  %s\n", checkErr)
+	}
+}
+
+// confirm asks a yes or no question, an empty answer taking the default one.
+func (p *prompter) confirm(question string, defaultYes bool) (bool, error) {
+	choices := "y/N"
+	if defaultYes {
+		choices = "Y/n"
+	}
+
+	answer, err := p.ask(fmt.Sprintf("%s [%s]", question, choices), func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		default:
+			return errors.New("answer y or n")
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(answer) == 0 {
+		return defaultYes, nil
+	}
+
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// checkOptionField returns a check accepting an answer when, once given to the option with set,
+// shellcligen.ValidateOption finds no problem in field.
+func checkOptionField(cli *shellcligen.CLIProgram, cliOption *shellcligen.CLIOption, field string,
+	set func(cliOption *shellcligen.CLIOption, answer string)) func(answer string) error {
+	return func(answer string) error {
+		candidate := *cliOption
+		set(&candidate, answer)
+
+		var errs shellcligen.ValidationErrors
+		if err := shellcligen.ValidateOption(cli, &candidate); errors.As(err, &errs) {
+			for _, validationError := range errs {
+				if validationError.Field == field {
+					return errors.New(validationError.Message)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func helpOption() shellcligen.CLIOption {
+	return shellcligen.CLIOption{ShortName: "h", LongName: "help", Help: true, Description: "show this help"}
+}
+
+// askOption asks for an option to add to the program, returning false when no more options are
+// wanted.
+func askOption(p *prompter, cli *shellcligen.CLIProgram) (shellcligen.CLIOption, bool, error) {
+	cliOption := shellcligen.CLIOption{}
+
+	longName, err := p.ask("Long name of the option, empty to finish", func(answer string) error {
+		if len(answer) == 0 {
+			return nil
+		}
+
+		return checkOptionField(cli, &cliOption, "long_name", func(cliOption *shellcligen.CLIOption, answer string) {
+			cliOption.LongName = answer
+		})(answer)
+	})
+	if errors.Is(err, io.EOF) || err == nil && len(longName) == 0 {
+		return cliOption, false, nil
+	}
+
+	if err != nil {
+		return cliOption, false, err
+	}
+
+	cliOption.LongName = longName
+
+	if cliOption.ShortName, err = p.ask("Short name", checkOptionField(cli, &cliOption, "short_name",
+		func(cliOption *shellcligen.CLIOption, answer string) {
+			cliOption.ShortName = answer
+		})); err != nil {
+		return cliOption, false, err
+	}
+
+	if cliOption.Description, err = p.ask("Description", nil); err != nil {
+		return cliOption, false, err
+	}
+
+	if cliOption.Required, err = p.confirm("Is it required?", false); err != nil {
+		return cliOption, false, err
+	}
+
+	if cliOption.ArgsRequired, err = p.confirm("Does it take a value?", false); err != nil {
+		return cliOption, false, err
+	}
+
+	conflicts, err := p.ask("Options it conflicts with, separated by spaces", checkOptionField(cli, &cliOption, "conflicts_with",
+		func(cliOption *shellcligen.CLIOption, answer string) {
+			cliOption.ConflictsWith = strings.Fields(answer)
+		}))
+	if err != nil {
+		return cliOption, false, err
+	}
+
+	cliOption.ConflictsWith = strings.Fields(conflicts)
+
+	return cliOption, true, nil
+}
+
+// checkProgramName accepts the names shellcligen.CLIProgram.Validate accepts.
+func checkProgramName(answer string) error {
+	var errs shellcligen.ValidationErrors
+	if err := (&shellcligen.CLIProgram{Name: answer}).Validate(); errors.As(err, &errs) {
+		return errors.New(errs[0].Message)
+	}
+
+	return nil
+}
+
+// askProgram asks for the name, the description and the options of a program.
+func askProgram(p *prompter) (shellcligen.CLIProgram, error) {
+	cli := shellcligen.CLIProgram{}
+
+	var err error
+
+	if cli.Name, err = p.ask("File name of the script, empty for script.sh", checkProgramName); err != nil {
+		return cli, err
+	}
+
+	if cli.Help, err = p.ask("Description of the program", nil); err != nil {
+		return cli, err
+	}
+
+	if cli.SafeFlags, err = p.confirm("Run the script with set -euo pipefail?", true); err != nil {
+		return cli, err
+	}
+
+	addHelp, err := p.confirm("Add a -h/--help option?", true)
+	if err != nil {
+		return cli, err
+	}
+
+	if addHelp {
+		cli.Options = append(cli.Options, helpOption())
+	}
+
+	for {
+		cliOption, more, err := askOption(p, &cli)
+		if err != nil || !more {
+			return cli, err
+		}
+
+		cli.Options = append(cli.Options, cliOption)
+		fmt.Fprintf(p.out, "  added -%s/--%s\n", cliOption.ShortName, cliOption.LongName)
+	}
+}
+
+// programFromFlags builds the program given with -name, -description and -option, checking each
+// option as the questions of the interactive mode do.
+func programFromFlags(name, description string, safeFlags, addHelp bool, options []shellcligen.CLIOption) (shellcligen.CLIProgram, error) {
+	cli := shellcligen.CLIProgram{Name: name, Help: description, SafeFlags: safeFlags}
+
+	if err := checkProgramName(name); err != nil {
+		return cli, err
+	}
+
+	if addHelp {
+		cli.Options = append(cli.Options, helpOption())
+	}
+
+	for i := range options {
+		if err := shellcligen.ValidateOption(&cli, &options[i]); err != nil {
+			return cli, err
+		}
+
+		cli.Options = append(cli.Options, options[i])
+	}
+
+	return cli, nil
+}
+
+func runInit(cmd *command, args []string) error {
+	flags, out := newFlagSet(cmd)
+	force := flags.Bool("force", false, "overwrite the spec if it exists")
+	noInput := flags.Bool("no-input", false, "do not ask anything, build the spec from the flags")
+	name := flags.String("name", "", "file name of the script, implies -no-input")
+	description := flags.String("description", "", "description of the program, implies -no-input")
+	safeFlags := flags.Bool("safe-flags", true, "run the script with set -euo pipefail")
+	addHelp := flags.Bool("help-option", true, "add a -h/--help option")
+
+	var options optionsFlag
+
+	flags.Var(&options, "option", "option to add, implies -no-input and can be repeated: "+
+		"short=a,long=article[,required][,arg][,conflicts=p|q][,description=text]")
+
+	if err := parseFlags(flags, out, args, 0, 1); err != nil {
+		return err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		*noInput = *noInput || f.Name == "name" || f.Name == "description" || f.Name == "option"
+	})
+
+	specFile := "cli.yml"
+	if flags.NArg() == 1 {
+		specFile = flags.Arg(0)
+	}
+
+	if _, err := os.Stat(specFile); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", specFile)
+	}
+
+	var (
+		cli shellcligen.CLIProgram
+		err error
+	)
+
+	if *noInput {
+		cli, err = programFromFlags(*name, *description, *safeFlags, *addHelp, options)
+	} else {
+		cli, err = askProgram(&prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	content, err := shellcligen.MarshalCommentedCLIProgram(&cli)
+	if err != nil {
+		return err
+	}
+
+	out.debug("%d options", len(cli.Options))
+
+	return writeOutput(specFile, content, out)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leogtzr/shellcligen"
+)
+
+func Test_optionsFlag_Set(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		value string
+		want  shellcligen.CLIOption
+		err   error
+	}
+
+	tests := []test{
+		{
+			value: "short=a,long=article,required,arg",
+			want:  shellcligen.CLIOption{ShortName: "a", LongName: "article", Required: true, ArgsRequired: true},
+		},
+		{
+			value: "short=p,long=page,conflicts=a|b,description=page to read, 1 by default",
+			want: shellcligen.CLIOption{
+				ShortName: "p", LongName: "page", ConflictsWith: []string{"a", "b"}, Description: "page to read, 1 by default",
+			},
+		},
+		{
+			value: "description=x, y,short=a",
+			want:  shellcligen.CLIOption{Description: "x, y,short=a"},
+		},
+		{
+			value: "long=usage,help",
+			want:  shellcligen.CLIOption{LongName: "usage", Help: true},
+		},
+		{value: "short=a,color=red", err: errInvalidOptionFlag},
+		{value: "short=a,,long=article", err: errInvalidOptionFlag},
+	}
+
+	for _, tt := range tests {
+		var options optionsFlag
+
+		err := options.Set(tt.value)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("got=%v, want=%v for [%s]", err, tt.err, tt.value)
+
+			continue
+		}
+
+		if tt.err != nil {
+			if len(options) != 0 {
+				t.Errorf("got=%+v, want no option for [%s]", options, tt.value)
+			}
+
+			continue
+		}
+
+		if want := (optionsFlag{tt.want}); !reflect.DeepEqual(options, want) {
+			t.Errorf("got=%+v, want=%+v for [%s]", options, want, tt.value)
+		}
+	}
+}
+
+func Test_askOption(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		input    string
+		want     shellcligen.CLIOption
+		wantMore bool
+		wantErr  bool
+	}
+
+	tests := []test{
+		{input: "\n", wantMore: false},
+		{input: "", wantMore: false},
+		{
+			input: "article\na\nfetches it\ny\nn\np\n",
+			want: shellcligen.CLIOption{
+				LongName: "article", ShortName: "a", Description: "fetches it", Required: true, ConflictsWith: []string{"p"},
+			},
+			wantMore: true,
+		},
+		{
+			input:    "article\na\n\n\nyes\n\n",
+			want:     shellcligen.CLIOption{LongName: "article", ShortName: "a", ArgsRequired: true, ConflictsWith: []string{}},
+			wantMore: true,
+		},
+		{
+			input:    "page\nart\np\na\n\n\n\n\n",
+			want:     shellcligen.CLIOption{LongName: "art", ShortName: "a", ConflictsWith: []string{}},
+			wantMore: true,
+		},
+		{
+			input:    "article\nab\na\n\nmaybe\nn\n\nx\np\n",
+			want:     shellcligen.CLIOption{LongName: "article", ShortName: "a", ConflictsWith: []string{"p"}},
+			wantMore: true,
+		},
+		{input: "article\n", want: shellcligen.CLIOption{LongName: "article"}, wantErr: true},
+		{input: "article\nab", want: shellcligen.CLIOption{LongName: "article"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cli := shellcligen.CLIProgram{Options: []shellcligen.CLIOption{{ShortName: "p", LongName: "page"}}}
+		p := prompter{in: bufio.NewReader(strings.NewReader(tt.input)), out: io.Discard}
+
+		got, more, err := askOption(&p, &cli)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("got=%v, want error %t for [%q]", err, tt.wantErr, tt.input)
+		}
+
+		if more != tt.wantMore || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got=%+v %t, want=%+v %t for [%q]", got, more, tt.want, tt.wantMore, tt.input)
+		}
+	}
+}
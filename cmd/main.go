@@ -1,43 +1,197 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+)
+
+// Exit statuses, usage errors exit like the generated scripts do.
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
 
-	"github.com/leogtzr/shellcligen"
+var (
+	// errUsage is returned when a command is not called as its help says.
+	errUsage = errors.New("usage error")
+
+	// errFailed is returned when the problems were already reported by the command.
+	errFailed = errors.New("failed")
 )
 
-func run() error {
-	inputFile := flag.String("input", "", "configuration file")
-	outputFile := flag.String("output", "", "output directory where the script will be generated")
+// command is a subcommand of shellcligen, like generate.
+type command struct {
+	name        string
+	arguments   string
+	description string
+	run         func(cmd *command, args []string) error
+}
+
+var commands = []*command{
+	{name: "generate", arguments: "[flags] <spec>", run: runGenerate,
+		description: "Generates the script, its configuration file, completions and documentation from a spec."},
+	{name: "validate", arguments: "[flags] <spec>...", run: runValidate,
+		description: "Validates specs, reporting every problem found as file:line:col."},
+	{name: "init", arguments: "[flags] [spec]", run: runInit,
+		description: "Writes a new spec to start from, cli.yml by default."},
+	{name: "fmt", arguments: "[flags] <spec>...", run: runFmt,
+		description: "Rewrites specs in the canonical layout of their format. Comments are not kept."},
+	{name: "docs", arguments: "[flags] <spec>", run: runDocs,
+		description: "Writes the man page or the Markdown documentation of a spec."},
+	{name: "completion", arguments: "[flags] <spec>", run: runCompletion,
+		description: "Writes the bash, zsh or fish completion script of a spec."},
+	{name: "import", arguments: "[flags] [file]", run: runImport,
+		description: "Builds a spec from a script calling getopt or from the --help output of a tool."},
+}
+
+// output prints what a command does according to the --quiet and --verbose flags. Messages go to
+// stderr so stdout only holds what a command generates.
+type output struct {
+	quiet, verbose bool
+	stderr         io.Writer
+}
+
+// info prints a summary of what was done, unless quiet.
+func (out *output) info(format string, args ...interface{}) {
+	if !out.quiet {
+		fmt.Fprintf(out.stderr, format+"\n", args...)
+	}
+}
+
+// warn prints a problem which does not stop the command, unless quiet.
+func (out *output) warn(format string, args ...interface{}) {
+	if !out.quiet {
+		fmt.Fprintf(out.stderr, "warning: "+format+"\n", args...)
+	}
+}
+
+// debug prints the details of what is done, when verbose.
+func (out *output) debug(format string, args ...interface{}) {
+	if out.verbose {
+		fmt.Fprintf(out.stderr, format+"\n", args...)
+	}
+}
+
+// newFlagSet returns the flags of a command along with the --quiet and --verbose ones shared by
+// every command.
+func newFlagSet(cmd *command) (*flag.FlagSet, *output) {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
 
-	flag.Parse()
+	out := &output{stderr: os.Stderr}
+	flags.BoolVar(&out.quiet, "quiet", false, "only print errors")
+	flags.BoolVar(&out.verbose, "verbose", false, "print the details of what is done")
 
-	if len(*inputFile) == 0 {
-		return shellcligen.ErrMissingRequiredArgument
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stdout, "usage: shellcligen %s %s\n\n%s\n\nFlags:\n", cmd.name, cmd.arguments, cmd.description)
+		flags.SetOutput(os.Stdout)
+		flags.PrintDefaults()
 	}
 
-	if len(*outputFile) == 0 {
-		return shellcligen.ErrMissingRequiredArgument
+	return flags, out
+}
+
+// parseFlags parses the arguments of a command, the flag package printing its help when asked for,
+// and checks the number of arguments left is within [minArgs, maxArgs], maxArgs being -1 for no limit.
+func parseFlags(flags *flag.FlagSet, out *output, args []string, minArgs, maxArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return fmt.Errorf("%s: %w", err, errUsage)
 	}
 
-	cli, err := shellcligen.ParseCLIProgram(*inputFile, *outputFile)
-	if err != nil {
-		return fmt.Errorf("error opening input file: %w", err)
+	switch {
+	case out.quiet && out.verbose:
+		return fmt.Errorf("--quiet and --verbose can not be used together: %w", errUsage)
+	case flags.NArg() < minArgs:
+		return fmt.Errorf("missing arguments: %w", errUsage)
+	case maxArgs != -1 && flags.NArg() > maxArgs:
+		return fmt.Errorf("too many arguments: %w", errUsage)
+	default:
+		return nil
 	}
+}
 
-	for _, opt := range cli.Options {
-		fmt.Println(opt)
-		fmt.Println(opt.ConflictsWith)
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
 	}
 
 	return nil
 }
 
-func main() {
-	if err := run(); err != nil {
+func printUsage(w io.Writer) {
+	var usageSb strings.Builder
+
+	usageSb.WriteString("usage: shellcligen <command> [flags] [arguments]\n\nCommands:\n")
+
+	for _, cmd := range commands {
+		usageSb.WriteString(fmt.Sprintf("  %-12s%s\n", cmd.name, cmd.description))
+	}
+
+	usageSb.WriteString("\nRun 'shellcligen <command> -h' for the help of a command.\n")
+
+	fmt.Fprint(w, usageSb.String())
+}
+
+// run runs the command given in args and returns the exit status.
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+
+		return exitUsage
+	}
+
+	name, args := args[0], args[1:]
+
+	switch {
+	case name == "-h" || name == "-help" || name == "--help" || name == "help" && len(args) == 0:
+		printUsage(os.Stdout)
+
+		return exitSuccess
+	case name == "help":
+		name, args = args[0], []string{"-h"}
+	case strings.HasPrefix(name, "-"):
+		// Before commands, flags were given to shellcligen itself to generate a script.
+		name, args = "generate", append([]string{name}, args...)
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		printUsage(os.Stderr)
+
+		return exitUsage
+	}
+
+	err := cmd.run(cmd, args)
+
+	switch {
+	case err == nil || errors.Is(err, flag.ErrHelp):
+		return exitSuccess
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "shellcligen %s: %s\nRun 'shellcligen %s -h' for help.\n",
+			cmd.name, strings.TrimSuffix(err.Error(), ": "+errUsage.Error()), cmd.name)
+
+		return exitUsage
+	case errors.Is(err, errFailed):
+		return exitFailure
+	default:
 		fmt.Fprintf(os.Stderr, "%s\n", err)
-		os.Exit(1)
+
+		return exitFailure
 	}
 }
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
@@ -0,0 +1,279 @@
+package shellcligen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// commandScope is the program itself or one of its commands, along with the options it inherits
+// from the program and its parent commands.
+type commandScope struct {
+	path      []string
+	cli       CLIProgram
+	inherited []CLIOption
+}
+
+func commandScopesFrom(path []string, cli CLIProgram, inherited []CLIOption) []commandScope {
+	scopes := []commandScope{{path: path, cli: cli, inherited: inherited}}
+
+	childInherited := make([]CLIOption, 0, len(inherited)+len(cli.Options))
+	childInherited = append(childInherited, inherited...)
+	childInherited = append(childInherited, cli.Options...)
+
+	for _, command := range cli.Commands {
+		childPath := make([]string, 0, len(path)+1)
+		childPath = append(childPath, path...)
+		childPath = append(childPath, strings.TrimSpace(command.Name))
+
+		scopes = append(scopes, commandScopesFrom(childPath, CLIProgram{
+			Name:        cli.Name,
+			Help:        command.Help,
+			Options:     command.Options,
+			Positionals: command.Positionals,
+			Commands:    command.Commands,
+		}, childInherited)...)
+	}
+
+	return scopes
+}
+
+// commandScopes returns the program scope first followed by every command, depth first.
+func commandScopes(cli *CLIProgram) []commandScope {
+	return commandScopesFrom(nil, *cli, nil)
+}
+
+// scopeProgram returns the scope as a program holding the inherited options first and then its own.
+func scopeProgram(scope *commandScope) CLIProgram {
+	options := make([]CLIOption, 0, len(scope.inherited)+len(scope.cli.Options))
+	options = append(options, scope.inherited...)
+	options = append(options, scope.cli.Options...)
+
+	return CLIProgram{
+		Name:        scope.cli.Name,
+		Help:        scope.cli.Help,
+		Options:     options,
+		Positionals: scope.cli.Positionals,
+		Commands:    scope.cli.Commands,
+		SafeFlags:   scope.cli.SafeFlags,
+	}
+}
+
+// allScopesOptions returns the options declared by the program and all of its commands.
+func allScopesOptions(cli *CLIProgram) []*CLIOption {
+	options := make([]*CLIOption, 0, len(cli.Options))
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		for j := range scopes[i].cli.Options {
+			options = append(options, &scopes[i].cli.Options[j])
+		}
+	}
+
+	return options
+}
+
+func commandPath(scope *commandScope) string {
+	return strings.Join(scope.path, " ")
+}
+
+func commandFunctionSuffix(scope *commandScope) string {
+	sanitized := make([]string, 0, len(scope.path))
+
+	for _, name := range scope.path {
+		sanitized = append(sanitized, sanitizeOptionName(name))
+	}
+
+	return strings.Join(sanitized, "__")
+}
+
+func usageFunctionName(scope *commandScope) string {
+	if len(scope.path) == 0 {
+		return "usage_root"
+	}
+
+	return "usage_command_" + commandFunctionSuffix(scope)
+}
+
+func parseFunctionName(scope *commandScope) string {
+	return "parse_command_" + commandFunctionSuffix(scope)
+}
+
+func indentLines(code, indent string) string {
+	var indentedSb strings.Builder
+
+	for _, line := range strings.SplitAfter(code, "\n") {
+		if len(strings.TrimSpace(line)) > 0 {
+			indentedSb.WriteString(indent)
+		}
+
+		indentedSb.WriteString(line)
+	}
+
+	return indentedSb.String()
+}
+
+func commandScopeCondition(scope *commandScope) string {
+	return fmt.Sprintf(`[[ "${command_path} " == "%s "* ]]`, commandPath(scope))
+}
+
+// guardCommandScope makes the code run only when the scope command, or one of its subcommands,
+// was invoked. Code of the program scope always runs.
+func guardCommandScope(scope *commandScope, code string) string {
+	if len(scope.path) == 0 || len(code) == 0 {
+		return code
+	}
+
+	return fmt.Sprintf("\nif %s; then\n%sfi\n",
+		commandScopeCondition(scope), indentLines(strings.TrimPrefix(code, "\n"), "    "))
+}
+
+func generateScopedSection(cli *CLIProgram, generate func(scope *commandScope) string) string {
+	var sectionSb strings.Builder
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		sectionSb.WriteString(guardCommandScope(&scopes[i], generate(&scopes[i])))
+	}
+
+	return sectionSb.String()
+}
+
+func generateScopeValidation(scope *commandScope) string {
+	program := scopeProgram(scope)
+
+	return generateRequiredOptionsCheck(&scope.cli) + generateConflictingOptionsCheck(&program, len(scope.inherited))
+}
+
+func generateCommandDispatch(scope *commandScope) string {
+	var dispatchSb strings.Builder
+
+	dispatchSb.WriteString(`
+if [[ "${#}" -eq 0 ]]; then
+    echo "missing command" >&2
+    usage >&2
+    exit 2
+fi
+
+case "${1}" in
+`)
+
+	for _, command := range scope.cli.Commands {
+		name := strings.TrimSpace(command.Name)
+		child := commandScope{path: append(append([]string{}, scope.path...), name)}
+
+		dispatchSb.WriteString(fmt.Sprintf(`%s)
+    shift
+    %s "${@}"
+    ;;
+`, name, parseFunctionName(&child)))
+	}
+
+	dispatchSb.WriteString(`*)
+    echo "unknown command: ${1}" >&2
+    usage >&2
+    exit 2
+    ;;
+esac
+`)
+
+	return dispatchSb.String()
+}
+
+// generateRootCommandDispatch generates the code routing the arguments left after the program
+// options to the invoked command, once it returns the arguments of the command are the ones left.
+func generateRootCommandDispatch(cli *CLIProgram) string {
+	if len(cli.Commands) == 0 {
+		return ""
+	}
+
+	scopes := commandScopes(cli)
+
+	return generateCommandDispatch(&scopes[0]) + `
+set -- "${command_args[@]+"${command_args[@]}"}"
+`
+}
+
+func generateCommandParser(scope *commandScope) string {
+	program := scopeProgram(scope)
+
+	commandsCode := "\ncommand_args=(\"${@}\")\n"
+	if len(scope.cli.Commands) > 0 {
+		commandsCode = generateCommandDispatch(scope)
+	}
+
+	var parserSb strings.Builder
+
+	// The parser template only renders strings, executing it can not fail.
+	_ = commandParserTmpl.Execute(&parserSb, commandParserData{
+		Function: parseFunctionName(scope),
+		Path:     commandPath(scope),
+		Getopt:   generateGetoptCall(&program),
+		CaseArms: indentLines(generateCaseArms(&program), "    "),
+		Commands: indentLines(commandsCode, "    "),
+	})
+
+	return parserSb.String()
+}
+
+func generateCommandParsers(cli *CLIProgram) string {
+	var parsersSb strings.Builder
+
+	scopes := commandScopes(cli)
+	for i := 1; i < len(scopes); i++ {
+		parsersSb.WriteString(generateCommandParser(&scopes[i]))
+		parsersSb.WriteString("\n")
+	}
+
+	return parsersSb.String()
+}
+
+func firstHelpLine(help string) string {
+	help = strings.TrimSpace(help)
+	if i := strings.Index(help, "\n"); i != -1 {
+		return help[:i]
+	}
+
+	return help
+}
+
+// validateCommands checks the command names of every scope are valid and unique, and that scopes
+// with commands do not declare positionals since their arguments belong to the commands.
+func validateCommands(cli *CLIProgram, regex *regexp.Regexp) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		scope := &scopes[i]
+		names := make(map[string]bool)
+		scopeErrs := make([]*ValidationError, 0)
+
+		if len(scope.cli.Commands) > 0 && len(scope.cli.Positionals) > 0 {
+			scopeErrs = append(scopeErrs, newValidationError(RuleInvalidCommand, ErrInvalidCommand,
+				scope.cli.Positionals[0].Position, "positionals", "positionals can not be declared along with commands"))
+		}
+
+		for _, command := range scope.cli.Commands {
+			name := strings.TrimSpace(command.Name)
+
+			switch {
+			case !regex.MatchString(name):
+				scopeErrs = append(scopeErrs, newValidationError(RuleInvalidCommand, ErrInvalidCommand,
+					command.Position, "commands", "invalid command name %q", command.Name))
+			case names[name]:
+				scopeErrs = append(scopeErrs, newValidationError(RuleInvalidCommand, ErrInvalidCommand,
+					command.Position, "commands", "command name %q is already used", name))
+			}
+
+			names[name] = true
+		}
+
+		for _, err := range scopeErrs {
+			err.Command = commandPath(scope)
+		}
+
+		errs = append(errs, scopeErrs...)
+	}
+
+	return errs
+}
@@ -0,0 +1,269 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func commandsTestProgram() CLIProgram {
+	return CLIProgram{
+		Help: "deployment tool",
+		Options: []CLIOption{
+			{ShortName: "v", LongName: "verbose"},
+		},
+		Commands: []Command{
+			{
+				Name: "deploy",
+				Help: "deploy the application\nto the given target",
+				Options: []CLIOption{
+					{ShortName: "e", LongName: "env", ArgsRequired: true, Required: true},
+				},
+				Positionals: []Positional{
+					{Name: "target", Required: true},
+				},
+			},
+			{
+				Name: "db",
+				Help: "database commands",
+				Commands: []Command{
+					{
+						Name: "migrate",
+						Options: []CLIOption{
+							{ShortName: "s", LongName: "steps", ArgsRequired: true, Type: "int", Default: "1"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_commandScopes(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	scopes := commandScopes(&cliProgram)
+
+	type want struct {
+		path      string
+		options   int
+		inherited int
+	}
+
+	wants := []want{
+		{path: "", options: 1, inherited: 0},
+		{path: "deploy", options: 1, inherited: 1},
+		{path: "db", options: 0, inherited: 1},
+		{path: "db migrate", options: 1, inherited: 1},
+	}
+
+	if len(scopes) != len(wants) {
+		t.Fatalf("got=%d scopes, want=%d", len(scopes), len(wants))
+	}
+
+	for i, w := range wants {
+		scope := &scopes[i]
+		if commandPath(scope) != w.path || len(scope.cli.Options) != w.options || len(scope.inherited) != w.inherited {
+			t.Errorf("got=[%s] with %d options and %d inherited, want=%+v",
+				commandPath(scope), len(scope.cli.Options), len(scope.inherited), w)
+		}
+	}
+}
+
+func Test_validateCommands(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliProgram CLIProgram
+		want       bool
+	}
+
+	tests := []test{
+		{
+			cliProgram: commandsTestProgram(),
+			want:       true,
+		},
+		{
+			cliProgram: CLIProgram{
+				Commands: []Command{{Name: "deploy"}, {Name: "deploy"}},
+			},
+			want: false,
+		},
+		{
+			cliProgram: CLIProgram{
+				Commands: []Command{{Name: "de ploy"}},
+			},
+			want: false,
+		},
+		// Arguments of programs with commands belong to the commands.
+		{
+			cliProgram: CLIProgram{
+				Positionals: []Positional{{Name: "file", Required: true}},
+				Commands:    []Command{{Name: "deploy"}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := len(validateCommands(&tt.cliProgram, cliOptionRegex)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t", got, tt.want)
+		}
+	}
+}
+
+func Test_guardCommandScope(t *testing.T) {
+	t.Parallel()
+
+	code := "\nif true; then\n    echo ok\nfi\n"
+
+	root := commandScope{}
+	if got := guardCommandScope(&root, code); got != code {
+		t.Errorf("got=[%s], want=[%s]", got, code)
+	}
+
+	command := commandScope{path: []string{"db", "migrate"}}
+	want := `
+if [[ "${command_path} " == "db migrate "* ]]; then
+    if true; then
+        echo ok
+    fi
+fi
+`
+
+	if got := guardCommandScope(&command, code); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateRootCommandDispatch(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+
+	want := `
+if [[ "${#}" -eq 0 ]]; then
+    echo "missing command" >&2
+    usage >&2
+    exit 2
+fi
+
+case "${1}" in
+deploy)
+    shift
+    parse_command_deploy "${@}"
+    ;;
+db)
+    shift
+    parse_command_db "${@}"
+    ;;
+*)
+    echo "unknown command: ${1}" >&2
+    usage >&2
+    exit 2
+    ;;
+esac
+
+set -- "${command_args[@]+"${command_args[@]}"}"
+`
+
+	if got := generateRootCommandDispatch(&cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+
+	cliProgram.Commands = nil
+	if got := generateRootCommandDispatch(&cliProgram); got != "" {
+		t.Errorf("got=[%s], want no dispatch", got)
+	}
+}
+
+func Test_generateCommandParsers(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateCommandParsers(&cliProgram)
+
+	for _, want := range []string{
+		"parse_command_deploy() {\n",
+		`    command_path="deploy"`,
+		`--options 've:' --long 'verbose,env:'`,
+		"        -v|--verbose)\n            v_option_flag=1\n",
+		"    command_args=(\"${@}\")\n}\n",
+		"parse_command_db() {\n",
+		`--options '+v' --long 'verbose'`,
+		"        parse_command_db__migrate \"${@}\"\n",
+		"parse_command_db__migrate() {\n",
+		`    command_path="db migrate"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("command parsers do not contain [%s]:\n%s", want, got)
+		}
+	}
+}
+
+func Test_generateUsage_commands(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateUsage(&cliProgram)
+
+	for _, want := range []string{
+		"usage_root() {\n    echo \"Usage: $(basename \"${0}\") [OPTIONS] <command> [ARGS]\"\n",
+		"Commands:\n  deploy         deploy the application\n  db             database commands\n",
+		"usage_command_deploy() {\n    echo \"Usage: $(basename \"${0}\") deploy [OPTIONS] <target>\"\n",
+		"Global options:\n  -v, --verbose",
+		"    \"db migrate\")\n        usage_command_db__migrate\n        ;;\n",
+		"    *)\n        usage_root\n        ;;\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("usage does not contain [%s]:\n%s", want, got)
+		}
+	}
+}
+
+func Test_generateScopeValidation(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", Required: true, ArgsRequired: true},
+			{ShortName: "p", LongName: "", ConflictsWith: []string{"article"}},
+		},
+	}
+
+	want := `
+if [[ "${a_option_flag}" -eq 0 ]]; then
+    echo "missing required option: -a/--article" >&2
+    exit 2
+fi
+
+if [[ "${a_option_flag}" -eq 1 && "${p_option_flag}" -eq 1 ]]; then
+    echo "option -a/--article conflicts with option -p" >&2
+    exit 2
+fi
+`
+
+	if got := generateScopeValidation(&commandScope{cli: cliProgram}); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateScopedSection(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateScopedSection(&cliProgram, generateScopeValidation)
+
+	want := `
+if [[ "${command_path} " == "deploy "* ]]; then
+    if [[ "${e_option_flag}" -eq 0 ]]; then
+        echo "missing required option: -e/--env" >&2
+        exit 2
+    fi
+fi
+`
+
+	if got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
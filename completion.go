@@ -0,0 +1,288 @@
+package shellcligen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var nonIdentifierRegex = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// scriptBaseName returns the script file name without its extension, completion files and
+// functions are named after it.
+func scriptBaseName(cli *CLIProgram) string {
+	name := scriptName(cli)
+
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func bashCompletionFileName(cli *CLIProgram) string {
+	return scriptBaseName(cli) + "-completion.bash"
+}
+
+func completionFunctionPrefix(cli *CLIProgram) string {
+	return "_" + nonIdentifierRegex.ReplaceAllString(scriptBaseName(cli), "_")
+}
+
+// optionNames returns the names of the option as typed on the command line.
+func optionNames(cliOption *CLIOption) []string {
+	names := make([]string, 0, 2)
+
+	if shortOptionName := strings.TrimSpace(cliOption.ShortName); len(shortOptionName) > 0 {
+		names = append(names, "-"+shortOptionName)
+	}
+
+	if longOptionName := strings.TrimSpace(cliOption.LongName); len(longOptionName) > 0 {
+		names = append(names, "--"+longOptionName)
+	}
+
+	return names
+}
+
+func scopeCasePattern(scope *commandScope, cliOption *CLIOption) string {
+	patterns := make([]string, 0, 2)
+
+	for _, name := range optionNames(cliOption) {
+		patterns = append(patterns, fmt.Sprintf(`"%s:%s"`, commandPath(scope), name))
+	}
+
+	return strings.Join(patterns, "|")
+}
+
+func generateBashTakesArgFunction(scopes []commandScope) string {
+	var functionSb strings.Builder
+
+	functionSb.WriteString(fmt.Sprintf("%s_option_takes_arg() {\n", completionFunctionPrefix(&scopes[0].cli)))
+	functionSb.WriteString("    case \"${1}:${2}\" in\n")
+
+	for i := range scopes {
+		program := scopeProgram(&scopes[i])
+
+		for j := range program.Options {
+			if program.Options[j].ArgsRequired {
+				functionSb.WriteString(fmt.Sprintf("    %s)\n        return 0\n        ;;\n",
+					scopeCasePattern(&scopes[i], &program.Options[j])))
+			}
+		}
+	}
+
+	functionSb.WriteString("    esac\n\n    return 1\n}\n")
+
+	return functionSb.String()
+}
+
+func generateBashIsCommandFunction(scopes []commandScope) string {
+	var functionSb strings.Builder
+
+	functionSb.WriteString(fmt.Sprintf("%s_is_command() {\n", completionFunctionPrefix(&scopes[0].cli)))
+	functionSb.WriteString("    case \"${1}:${2}\" in\n")
+
+	for i := range scopes {
+		for _, command := range scopes[i].cli.Commands {
+			functionSb.WriteString(fmt.Sprintf("    \"%s:%s\")\n        return 0\n        ;;\n",
+				commandPath(&scopes[i]), strings.TrimSpace(command.Name)))
+		}
+	}
+
+	functionSb.WriteString("    esac\n\n    return 1\n}\n")
+
+	return functionSb.String()
+}
+
+// bashValueCompletion returns the compgen call completing the values of the option, or an empty
+// string for values which can not be completed.
+func bashValueCompletion(cliOption *CLIOption) string {
+	switch optionType(cliOption) {
+	case enumType:
+		return fmt.Sprintf(`COMPREPLY=($(compgen -W "%s" -- "${3}"))`, strings.Join(cliOption.Choices, " "))
+	case fileType, pathType:
+		return `compopt -o filenames 2> /dev/null
+        COMPREPLY=($(compgen -f -- "${3}"))`
+	case dirType:
+		return `compopt -o filenames 2> /dev/null
+        COMPREPLY=($(compgen -d -- "${3}"))`
+	default:
+		return ""
+	}
+}
+
+func generateBashCompleteValueFunction(scopes []commandScope) string {
+	var functionSb strings.Builder
+
+	functionSb.WriteString(fmt.Sprintf("%s_complete_value() {\n", completionFunctionPrefix(&scopes[0].cli)))
+	functionSb.WriteString("    case \"${1}:${2}\" in\n")
+
+	for i := range scopes {
+		program := scopeProgram(&scopes[i])
+
+		for j := range program.Options {
+			cliOption := &program.Options[j]
+
+			completion := bashValueCompletion(cliOption)
+			if !cliOption.ArgsRequired || len(completion) == 0 {
+				continue
+			}
+
+			functionSb.WriteString(fmt.Sprintf("    %s)\n        %s\n        ;;\n",
+				scopeCasePattern(&scopes[i], cliOption), completion))
+		}
+	}
+
+	functionSb.WriteString("    esac\n}\n")
+
+	return functionSb.String()
+}
+
+// generateBashScopeOptionCandidates lists the options of the scope, leaving out the ones which
+// conflict with an option already on the command line.
+func generateBashScopeOptionCandidates(scope *commandScope) string {
+	var candidatesSb strings.Builder
+
+	program := scopeProgram(scope)
+	pairs := conflictingOptionPairs(&program)
+
+	for i := range program.Options {
+		names := optionNames(&program.Options[i])
+		conflicts := make([]string, 0)
+
+		for _, pair := range pairs {
+			switch i {
+			case pair[0]:
+				conflicts = append(conflicts, optionNames(&program.Options[pair[1]])...)
+			case pair[1]:
+				conflicts = append(conflicts, optionNames(&program.Options[pair[0]])...)
+			}
+		}
+
+		if len(conflicts) == 0 {
+			candidatesSb.WriteString(fmt.Sprintf("        candidates+=(%s)\n", strings.Join(names, " ")))
+
+			continue
+		}
+
+		candidatesSb.WriteString(fmt.Sprintf(`        if ! %s_option_used %s; then
+            candidates+=(%s)
+        fi
+`, completionFunctionPrefix(&scope.cli), strings.Join(conflicts, " "), strings.Join(names, " ")))
+	}
+
+	return candidatesSb.String()
+}
+
+func generateBashCompletionFunction(scopes []commandScope) string {
+	var functionSb strings.Builder
+
+	prefix := completionFunctionPrefix(&scopes[0].cli)
+
+	functionSb.WriteString(fmt.Sprintf(`%s_completion() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local scope=""
+    local used=()
+    local candidates=()
+    local i word
+
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        word="${COMP_WORDS[i]}"
+
+        if [[ "${word}" == -* ]]; then
+            used+=("${word%%%%=*}")
+
+            if [[ "${word}" != *=* ]] && %s_option_takes_arg "${scope}" "${word}"; then
+                i=$((i + 1))
+            fi
+        elif %s_is_command "${scope}" "${word}"; then
+            scope="${scope:+${scope} }${word}"
+        fi
+    done
+
+    if [[ "${COMP_CWORD}" -gt 1 ]] && %s_option_takes_arg "${scope}" "${COMP_WORDS[COMP_CWORD-1]}"; then
+        %s_complete_value "${scope}" "${COMP_WORDS[COMP_CWORD-1]}" "${cur}"
+        return 0
+    fi
+
+    if [[ "${cur}" == -* ]]; then
+        case "${scope}" in
+`, prefix, prefix, prefix, prefix, prefix))
+
+	for i := range scopes {
+		functionSb.WriteString(fmt.Sprintf("        \"%s\")\n", commandPath(&scopes[i])))
+		functionSb.WriteString(indentLines(generateBashScopeOptionCandidates(&scopes[i]), "    "))
+		functionSb.WriteString("            ;;\n")
+	}
+
+	functionSb.WriteString(`        esac
+
+        COMPREPLY=($(compgen -W "${candidates[*]}" -- "${cur}"))
+        return 0
+    fi
+
+    case "${scope}" in
+`)
+
+	for i := range scopes {
+		if len(scopes[i].cli.Commands) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(scopes[i].cli.Commands))
+		for _, command := range scopes[i].cli.Commands {
+			names = append(names, strings.TrimSpace(command.Name))
+		}
+
+		functionSb.WriteString(fmt.Sprintf(`    "%s")
+        COMPREPLY=($(compgen -W "%s" -- "${cur}"))
+        return 0
+        ;;
+`, commandPath(&scopes[i]), strings.Join(names, " ")))
+	}
+
+	functionSb.WriteString(`    esac
+
+    compopt -o filenames 2> /dev/null
+    COMPREPLY=($(compgen -f -- "${cur}"))
+}
+`)
+
+	return functionSb.String()
+}
+
+// generateBashCompletion generates a bash completion script for the generated script, completing
+// options, commands and option values from their types.
+func generateBashCompletion(cli *CLIProgram) string {
+	var completionSb strings.Builder
+
+	scopes := commandScopes(cli)
+	prefix := completionFunctionPrefix(cli)
+
+	completionSb.WriteString(fmt.Sprintf(`# Bash completion for %s.
+#
+# Source this file or copy it to your bash-completion directory to enable it.
+
+%s_option_used() {
+    local name option
+
+    for name in "${@}"; do
+        for option in "${used[@]+"${used[@]}"}"; do
+            if [[ "${option}" == "${name}" ]]; then
+                return 0
+            fi
+        done
+    done
+
+    return 1
+}
+
+`, scriptName(cli), prefix))
+
+	completionSb.WriteString(generateBashTakesArgFunction(scopes))
+	completionSb.WriteString("\n")
+	completionSb.WriteString(generateBashIsCommandFunction(scopes))
+	completionSb.WriteString("\n")
+	completionSb.WriteString(generateBashCompleteValueFunction(scopes))
+	completionSb.WriteString("\n")
+	completionSb.WriteString(generateBashCompletionFunction(scopes))
+	completionSb.WriteString(fmt.Sprintf("\ncomplete -F %s_completion %s\n", prefix, scriptName(cli)))
+
+	return completionSb.String()
+}
@@ -0,0 +1,125 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_optionNames(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{cliOption: CLIOption{ShortName: "v", LongName: "verbose"}, want: "-v --verbose"},
+		{cliOption: CLIOption{ShortName: "", LongName: "verbose"}, want: "--verbose"},
+		{cliOption: CLIOption{ShortName: "v", LongName: " "}, want: "-v"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(optionNames(&tt.cliOption), " "); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_bashCompletionFileName(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cli        CLIProgram
+		wantFile   string
+		wantPrefix string
+	}
+
+	tests := []test{
+		{cli: CLIProgram{}, wantFile: "script-completion.bash", wantPrefix: "_script"},
+		{cli: CLIProgram{Name: "fetch-articles"}, wantFile: "fetch-articles-completion.bash", wantPrefix: "_fetch_articles"},
+	}
+
+	for _, tt := range tests {
+		if got := bashCompletionFileName(&tt.cli); got != tt.wantFile {
+			t.Errorf("got=%s, want=%s", got, tt.wantFile)
+		}
+
+		if got := completionFunctionPrefix(&tt.cli); got != tt.wantPrefix {
+			t.Errorf("got=%s, want=%s", got, tt.wantPrefix)
+		}
+	}
+}
+
+func Test_bashValueCompletion(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{
+			cliOption: CLIOption{ArgsRequired: true, Type: "enum", Choices: []string{"json", "yaml"}},
+			want:      `COMPREPLY=($(compgen -W "json yaml" -- "${3}"))`,
+		},
+		{
+			cliOption: CLIOption{ArgsRequired: true, Type: "dir"},
+			want:      "compopt -o filenames 2> /dev/null\n        COMPREPLY=($(compgen -d -- \"${3}\"))",
+		},
+		{
+			cliOption: CLIOption{ArgsRequired: true, Type: "int"},
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := bashValueCompletion(&tt.cliOption); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateBashScopeOptionCandidates(t *testing.T) {
+	t.Parallel()
+
+	scope := commandScope{
+		cli: CLIProgram{
+			Options: []CLIOption{
+				{ShortName: "a", LongName: "article"},
+				{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}},
+			},
+		},
+	}
+
+	want := `        if ! _script_option_used -p --page; then
+            candidates+=(-a --article)
+        fi
+        if ! _script_option_used -a --article; then
+            candidates+=(-p --page)
+        fi
+`
+
+	if got := generateBashScopeOptionCandidates(&scope); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateBashCompletion(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateBashCompletion(&cliProgram)
+
+	for _, want := range []string{
+		"    \"deploy:-e\"|\"deploy:--env\")\n        return 0\n",
+		"    \"db:migrate\")\n        return 0\n",
+		"    \"db\")\n        COMPREPLY=($(compgen -W \"migrate\" -- \"${cur}\"))\n",
+		"complete -F _script_completion script.sh\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("bash completion does not contain [%s]:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func configKeyName(cliOption *CLIOption) string {
+	longOptionName := strings.TrimSpace(cliOption.LongName)
+	if len(longOptionName) > 0 {
+		return longOptionName
+	}
+
+	return strings.TrimSpace(cliOption.ShortName)
+}
+
+// scopedConfigKeyName prefixes the key of command options with the command path, like
+// `db.migrate.target`, so commands can declare options with the same name.
+func scopedConfigKeyName(scope *commandScope, cliOption *CLIOption) string {
+	if len(scope.path) == 0 {
+		return configKeyName(cliOption)
+	}
+
+	return strings.Join(scope.path, ".") + "." + configKeyName(cliOption)
+}
+
+// alreadySetVariableName is the variable remembering whether the option was given on the command
+// line or through its environment variable, in which case script.conf must not override it.
+func alreadySetVariableName(cliOption *CLIOption) string {
+	return fmt.Sprintf("%s_already_set", sanitizeOptionName(optionName(cliOption)))
+}
+
+// commandLineVariableName is the variable remembering whether the option was given on the command
+// line, before its environment variable and script.conf are read.
+func commandLineVariableName(cliOption *CLIOption) string {
+	return fmt.Sprintf("%s_command_line", sanitizeOptionName(optionName(cliOption)))
+}
+
+// conflictingPartners returns the options each option conflicts with in any scope, keyed by the
+// variable the option is given on the command line in. An option is not read from its environment
+// variable nor from script.conf when one of them was given on the command line, the command line
+// overrides those values instead of conflicting with them.
+func conflictingPartners(cli *CLIProgram) map[string][]*CLIOption {
+	partners := make(map[string][]*CLIOption)
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		program := scopeProgram(&scopes[i])
+
+		for _, pair := range conflictingOptionPairs(&program) {
+			first, second := &program.Options[pair[0]], &program.Options[pair[1]]
+			partners[commandLineVariableName(first)] = append(partners[commandLineVariableName(first)], second)
+			partners[commandLineVariableName(second)] = append(partners[commandLineVariableName(second)], first)
+		}
+	}
+
+	return partners
+}
+
+// partnersNotGivenCondition returns the conditions of a test checking none of the conflicting
+// partners of the option was given on the command line.
+func partnersNotGivenCondition(cliOption *CLIOption, partners map[string][]*CLIOption) string {
+	var conditionSb strings.Builder
+
+	given := make(map[string]bool)
+
+	for _, partner := range partners[commandLineVariableName(cliOption)] {
+		if given[commandLineVariableName(partner)] {
+			continue
+		}
+
+		given[commandLineVariableName(partner)] = true
+
+		conditionSb.WriteString(fmt.Sprintf(` && "${%s:-0}" -eq 0`, commandLineVariableName(partner)))
+	}
+
+	return conditionSb.String()
+}
+
+// generateCommandLineFlags remembers which of the options with conflicts were given on the command
+// line, it runs before the environment variables and script.conf are read.
+func generateCommandLineFlags(cli *CLIProgram, partners map[string][]*CLIOption) string {
+	var flagsSb strings.Builder
+
+	remembered := make(map[string]bool)
+
+	for _, cliOption := range allScopesOptions(cli) {
+		name := commandLineVariableName(cliOption)
+		if len(partners[name]) == 0 || remembered[name] {
+			continue
+		}
+
+		remembered[name] = true
+
+		flagsSb.WriteString(fmt.Sprintf("%s=\"${%s}\"\n", name, flagOptionName(cliOption)))
+	}
+
+	if flagsSb.Len() == 0 {
+		return ""
+	}
+
+	return "\n" + flagsSb.String()
+}
+
+// configurableOptions returns the options that can be set from script.conf, the help option
+// is left out since it makes no sense to set it from a file.
+func configurableOptions(cli *CLIProgram) []*CLIOption {
+	options := make([]*CLIOption, 0, len(cli.Options))
+
+	for i := range cli.Options {
+		if !cli.Options[i].Help {
+			options = append(options, &cli.Options[i])
+		}
+	}
+
+	return options
+}
+
+func generateConfigFile(cli *CLIProgram) string {
+	var confSb strings.Builder
+
+	confSb.WriteString(fmt.Sprintf("# Configuration file for %s.\n", scriptName(cli)))
+	confSb.WriteString("#\n")
+	confSb.WriteString("# Each line is a key=value pair, blank lines and lines starting with '#' are ignored.\n")
+	confSb.WriteString("# Values given on the command line or through environment variables take precedence\n")
+	confSb.WriteString("# over the ones in this file, default values are used when nothing else is given.\n")
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		generateScopeConfigFile(&confSb, &scopes[i])
+	}
+
+	return confSb.String()
+}
+
+// configComment comments out every line of text, so multi-line descriptions are not read as keys.
+func configComment(text string) string {
+	var commentSb strings.Builder
+
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); len(line) == 0 {
+			commentSb.WriteString("#\n")
+
+			continue
+		}
+
+		commentSb.WriteString("# " + line + "\n")
+	}
+
+	return commentSb.String()
+}
+
+func generateScopeConfigFile(confSb *strings.Builder, scope *commandScope) {
+	options := configurableOptions(&scope.cli)
+	if len(scope.path) > 0 && len(options) > 0 {
+		confSb.WriteString(fmt.Sprintf("\n# Options of the `%s` command.\n", commandPath(scope)))
+	}
+
+	for _, cliOption := range options {
+		confSb.WriteString("\n")
+
+		if description := strings.TrimSpace(cliOption.Description); len(description) > 0 {
+			confSb.WriteString(configComment(description))
+		}
+
+		confSb.WriteString(fmt.Sprintf("# Option: %s\n", displayOptionName(cliOption)))
+
+		if cliOption.Required {
+			confSb.WriteString("# Required: yes\n")
+		} else {
+			confSb.WriteString("# Required: no\n")
+		}
+
+		if env := strings.TrimSpace(cliOption.Env); len(env) > 0 {
+			confSb.WriteString(fmt.Sprintf("# Environment variable: %s\n", env))
+		}
+
+		if cliOption.ArgsRequired {
+			confSb.WriteString(fmt.Sprintf("# Type: %s\n", optionType(cliOption)))
+			confSb.WriteString(fmt.Sprintf("#%s=%s\n", scopedConfigKeyName(scope, cliOption), cliOption.Default))
+		} else {
+			confSb.WriteString("# Accepted values: true, false\n")
+			confSb.WriteString(fmt.Sprintf("#%s=false\n", scopedConfigKeyName(scope, cliOption)))
+		}
+	}
+}
+
+func generateConfigKeyAssignment(scope *commandScope, cliOption *CLIOption, partners map[string][]*CLIOption) string {
+	var assignmentSb strings.Builder
+
+	notGiven := partnersNotGivenCondition(cliOption, partners)
+
+	if cliOption.ArgsRequired {
+		if validationCall := typeValidationCall(cliOption, "${value}"); len(validationCall) > 0 {
+			assignmentSb.WriteString(validationCall + "\n")
+		}
+
+		assignmentSb.WriteString(fmt.Sprintf(`if [[ "${%s}" -eq 0%s ]]; then
+    %s=1
+    %s=("${value}")
+fi
+`, alreadySetVariableName(cliOption), notGiven, flagOptionName(cliOption), argOptionName(cliOption)))
+
+		return assignmentSb.String()
+	}
+
+	assignmentSb.WriteString(fmt.Sprintf(`case "${value}" in
+true) value=1 ;;
+false) value=0 ;;
+*)
+    echo "${config_file}:${line_number}: invalid value for %s: expected true or false" >&2
+    exit 2
+    ;;
+esac
+if [[ "${%s}" -eq 0%s ]]; then
+    %s="${value}"
+fi
+`, scopedConfigKeyName(scope, cliOption), alreadySetVariableName(cliOption), notGiven, flagOptionName(cliOption)))
+
+	return assignmentSb.String()
+}
+
+// generateConfigKeyCase generates the case arm of an option key, keys of commands which were not
+// invoked are accepted but ignored.
+func generateConfigKeyCase(scope *commandScope, cliOption *CLIOption, partners map[string][]*CLIOption) string {
+	assignment := generateConfigKeyAssignment(scope, cliOption, partners)
+	if len(scope.path) > 0 {
+		assignment = fmt.Sprintf("if %s; then\n%sfi\n", commandScopeCondition(scope), indentLines(assignment, "    "))
+	}
+
+	return fmt.Sprintf("        %s)\n%s            ;;\n",
+		scopedConfigKeyName(scope, cliOption), indentLines(assignment, "            "))
+}
+
+// generateConfigLoader generates a load_config function which reads script.conf line by line
+// instead of sourcing it, so the file can never run arbitrary code.
+func generateConfigLoader(cli *CLIProgram) string {
+	var loaderSb strings.Builder
+
+	scopes := commandScopes(cli)
+	partners := conflictingPartners(cli)
+	declared := make(map[string]bool)
+
+	loaderSb.WriteString(`load_config() {
+    local config_file="${1}"
+    local line_number=0
+    local line key value
+`)
+
+	for i := range scopes {
+		for _, cliOption := range configurableOptions(&scopes[i].cli) {
+			if declared[alreadySetVariableName(cliOption)] {
+				continue
+			}
+
+			declared[alreadySetVariableName(cliOption)] = true
+
+			loaderSb.WriteString(fmt.Sprintf("    local %s=\"${%s}\"\n", alreadySetVariableName(cliOption), flagOptionName(cliOption)))
+		}
+	}
+
+	loaderSb.WriteString(`
+    [[ -f "${config_file}" ]] || return 0
+
+    while IFS= read -r line || [[ -n "${line}" ]]; do
+        line_number=$((line_number + 1))
+        line="${line#"${line%%[![:space:]]*}"}"
+
+        if [[ -z "${line}" || "${line}" == \#* ]]; then
+            continue
+        fi
+
+        if [[ "${line}" != *=* ]]; then
+            echo "${config_file}:${line_number}: expected key=value" >&2
+            exit 2
+        fi
+
+        key="${line%%=*}"
+        key="${key%"${key##*[![:space:]]}"}"
+        value="${line#*=}"
+        value="${value#"${value%%[![:space:]]*}"}"
+        value="${value%"${value##*[![:space:]]}"}"
+
+        if [[ "${value}" =~ ^\"(.*)\"$ || "${value}" =~ ^\'(.*)\'$ ]]; then
+            value="${BASH_REMATCH[1]}"
+        fi
+
+        case "${key}" in
+`)
+
+	for i := range scopes {
+		for _, cliOption := range configurableOptions(&scopes[i].cli) {
+			loaderSb.WriteString(generateConfigKeyCase(&scopes[i], cliOption, partners))
+		}
+	}
+
+	loaderSb.WriteString(`        *)
+            echo "${config_file}:${line_number}: unknown key: ${key}" >&2
+            exit 2
+            ;;
+        esac
+    done < "${config_file}"
+}
+`)
+
+	return loaderSb.String()
+}
+
+func generateConfigLoaderCall(cli *CLIProgram) string {
+	return fmt.Sprintf(`load_config "$(dirname "${BASH_SOURCE[0]}")/%s"`, configFileName(cli))
+}
+
+func generateEnvironmentCheck(cliOption *CLIOption, partners map[string][]*CLIOption) string {
+	env := strings.TrimSpace(cliOption.Env)
+	envExpression := fmt.Sprintf("${%s}", env)
+	notGiven := partnersNotGivenCondition(cliOption, partners)
+
+	if !cliOption.ArgsRequired {
+		return fmt.Sprintf(`
+if [[ "${%s}" -eq 0 && -n "${%s:-}"%s ]]; then
+    case "%s" in
+    true) %s=1 ;;
+    false) ;;
+    *)
+        echo "invalid value for %s: expected true or false" >&2
+        exit 2
+        ;;
+    esac
+fi
+`, flagOptionName(cliOption), env, notGiven, envExpression, flagOptionName(cliOption), env)
+	}
+
+	var envSb strings.Builder
+
+	envSb.WriteString(fmt.Sprintf(`
+if [[ "${%s}" -eq 0 && -n "${%s:-}"%s ]]; then
+`, flagOptionName(cliOption), env, notGiven))
+
+	if validationCall := typeValidationCall(cliOption, envExpression); len(validationCall) > 0 {
+		envSb.WriteString(fmt.Sprintf("    %s\n", validationCall))
+	}
+
+	envSb.WriteString(fmt.Sprintf(`    %s=1
+    %s=("%s")
+fi
+`, flagOptionName(cliOption), argOptionName(cliOption), envExpression))
+
+	return envSb.String()
+}
+
+// generateEnvironment generates the code reading option values from their environment variables,
+// it runs right after the command line is parsed so it only fills options which were not given,
+// and whose conflicting partners, see conflictingPartners, were not given either.
+func generateEnvironment(cli *CLIProgram, partners map[string][]*CLIOption) string {
+	var envSb strings.Builder
+
+	for _, cliOption := range configurableOptions(cli) {
+		if len(strings.TrimSpace(cliOption.Env)) > 0 {
+			envSb.WriteString(generateEnvironmentCheck(cliOption, partners))
+		}
+	}
+
+	return envSb.String()
+}
+
+// generateDefaults generates the code assigning default values, it runs after script.conf is
+// loaded so the defaults only fill the options nobody set. Defaults leave the option flag unset,
+// hence they never satisfy required options nor trigger conflicts.
+func generateDefaults(cli *CLIProgram) string {
+	var defaultsSb strings.Builder
+
+	for _, cliOption := range configurableOptions(cli) {
+		if !cliOption.ArgsRequired || len(cliOption.Default) == 0 {
+			continue
+		}
+
+		defaultsSb.WriteString(fmt.Sprintf(`
+if [[ "${%s}" -eq 0 ]]; then
+    %s=(%s)
+fi
+`, flagOptionName(cliOption), argOptionName(cliOption), shellQuote(cliOption.Default)))
+	}
+
+	return defaultsSb.String()
+}
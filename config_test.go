@@ -0,0 +1,210 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_configKeyName(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{
+			cliOption: CLIOption{ShortName: "a", LongName: "article"},
+			want:      "article",
+		},
+		{
+			cliOption: CLIOption{ShortName: "v", LongName: ""},
+			want:      "v",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := configKeyName(&tt.cliOption); got != tt.want {
+			t.Errorf("got=%s, want=%s", got, tt.want)
+		}
+	}
+}
+
+func Test_generateConfigFile(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", Required: true, ArgsRequired: true, Description: "article to read"},
+			{ShortName: "v", LongName: "verbose"},
+			{ShortName: "h", LongName: "help", Help: true},
+		},
+	}
+
+	want := `# Configuration file for script.sh.
+#
+# Each line is a key=value pair, blank lines and lines starting with '#' are ignored.
+# Values given on the command line or through environment variables take precedence
+# over the ones in this file, default values are used when nothing else is given.
+
+# article to read
+# Option: -a/--article
+# Required: yes
+# Type: string
+#article=
+
+# Option: -v/--verbose
+# Required: no
+# Accepted values: true, false
+#verbose=false
+`
+
+	if got := generateConfigFile(&cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateConfigLoader(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true},
+			{ShortName: "h", LongName: "help", Help: true},
+		},
+	}
+
+	got := generateConfigLoader(&cliProgram)
+
+	for _, want := range []string{
+		`local a_already_set="${a_option_flag}"`,
+		"        article)\n",
+		`a_arg=("${value}")`,
+		`unknown key: ${key}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config loader does not contain [%s]:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "source") || strings.Contains(got, "eval") || strings.Contains(got, "help)") {
+		t.Errorf("unexpected content in config loader:\n%s", got)
+	}
+}
+
+func Test_generateEnvironment(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "c", LongName: "count", ArgsRequired: true, Type: "int", Env: "COUNT"},
+			{ShortName: "v", LongName: "verbose", Env: "VERBOSE"},
+			{ShortName: "n", LongName: "name", ArgsRequired: true},
+		},
+	}
+
+	want := `
+if [[ "${c_option_flag}" -eq 0 && -n "${COUNT:-}" ]]; then
+    validate_int "-c/--count" "${COUNT}"
+    c_option_flag=1
+    c_arg=("${COUNT}")
+fi
+
+if [[ "${v_option_flag}" -eq 0 && -n "${VERBOSE:-}" ]]; then
+    case "${VERBOSE}" in
+    true) v_option_flag=1 ;;
+    false) ;;
+    *)
+        echo "invalid value for VERBOSE: expected true or false" >&2
+        exit 2
+        ;;
+    esac
+fi
+`
+
+	if got := generateEnvironment(&cliProgram, conflictingPartners(&cliProgram)); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateEnvironment_conflicts(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true, Env: "ARTICLE"},
+			{ShortName: "p", LongName: "page", ArgsRequired: true, ConflictsWith: []string{"a"}},
+			{ShortName: "v", LongName: "verbose"},
+		},
+	}
+
+	partners := conflictingPartners(&cliProgram)
+
+	wantFlags := `
+a_command_line="${a_option_flag}"
+p_command_line="${p_option_flag}"
+`
+	if got := generateCommandLineFlags(&cliProgram, partners); got != wantFlags {
+		t.Errorf("got=[%s], want=[%s]", got, wantFlags)
+	}
+
+	env := generateEnvironment(&cliProgram, partners)
+	if want := `if [[ "${a_option_flag}" -eq 0 && -n "${ARTICLE:-}" && "${p_command_line:-0}" -eq 0 ]]; then`; !strings.Contains(env, want) {
+		t.Errorf("environment does not contain [%s]:\n%s", want, env)
+	}
+
+	loader := generateConfigLoader(&cliProgram)
+	for _, want := range []string{
+		`if [[ "${a_already_set}" -eq 0 && "${p_command_line:-0}" -eq 0 ]]; then`,
+		`if [[ "${p_already_set}" -eq 0 && "${a_command_line:-0}" -eq 0 ]]; then`,
+		`if [[ "${v_already_set}" -eq 0 ]]; then`,
+	} {
+		if !strings.Contains(loader, want) {
+			t.Errorf("config loader does not contain [%s]:\n%s", want, loader)
+		}
+	}
+}
+
+func Test_generateDefaults(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "n", LongName: "name", ArgsRequired: true, Default: "it's me"},
+			{ShortName: "p", LongName: "page", ArgsRequired: true},
+		},
+	}
+
+	want := `
+if [[ "${n_option_flag}" -eq 0 ]]; then
+    n_arg=('it'\''s me')
+fi
+`
+
+	if got := generateDefaults(&cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_scopedConfigKeyName(t *testing.T) {
+	t.Parallel()
+
+	cliOption := CLIOption{ShortName: "s", LongName: "steps"}
+
+	type test struct {
+		scope commandScope
+		want  string
+	}
+
+	tests := []test{
+		{scope: commandScope{}, want: "steps"},
+		{scope: commandScope{path: []string{"db", "migrate"}}, want: "db.migrate.steps"},
+	}
+
+	for _, tt := range tests {
+		if got := scopedConfigKeyName(&tt.scope, &cliOption); got != tt.want {
+			t.Errorf("got=%s, want=%s", got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,32 @@
 package shellcligen
 
 const (
-	scriptFileName               = "script.sh"
-	scriptConfigFileName         = "script.conf"
-	templateWithConflictChecking = `hello`
-	safeFlagsTemplateTag         = `@safe_flags@`
-	safeFlagsTemplate            = `
-set -o errexit
-set -o nounset 
-set -o pipefail	
+	scriptFileName        = "script.sh"
+	scriptConfigFileName  = "script.conf"
+	commandParserTemplate = `{{ .Function }}() {
+    local opts
+
+    command_path="{{ .Path }}"
+    opts=$({{ .Getopt }} -- "${@}") || {
+        usage >&2
+        exit 2
+    }
+
+    eval set -- "${opts}"
+
+    while true; do
+        case "${1}" in
+{{ .CaseArms }}        --)
+            shift
+            break
+            ;;
+        *)
+            echo "unexpected option: ${1}" >&2
+            usage >&2
+            exit 2
+            ;;
+        esac
+    done
+{{ .Commands }}}
 `
 )
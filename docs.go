@@ -0,0 +1,124 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exitStatus is an exit status of the generated script, documented by the man page and the
+// Markdown reference.
+type exitStatus struct {
+	code        int
+	description string
+}
+
+var exitStatuses = []exitStatus{
+	{code: 0, description: "Success, or the usage was requested."},
+	{
+		code: 2,
+		description: "Invalid usage: an unknown, missing, conflicting or invalid option, a wrong number of " +
+			"arguments or an invalid configuration file.",
+	},
+}
+
+const otherExitStatusDescription = "Any other status comes from the command which failed in the script body."
+
+// scriptExample is an invocation of the generated script along with what it does.
+type scriptExample struct {
+	description string
+	command     string
+}
+
+// exampleValue returns the value used for an option in the examples, its default when it has one or
+// a placeholder otherwise.
+func exampleValue(cliOption *CLIOption) string {
+	switch {
+	case len(cliOption.Default) > 0:
+		return shellQuote(cliOption.Default)
+	case optionType(cliOption) == enumType && len(cliOption.Choices) > 0:
+		return cliOption.Choices[0]
+	default:
+		return strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(argPlaceholder(cliOption), "<"), ">"))
+	}
+}
+
+func requiredOptionsExample(cli *CLIProgram) []string {
+	parts := make([]string, 0)
+
+	for i := range cli.Options {
+		cliOption := &cli.Options[i]
+		if !cliOption.Required {
+			continue
+		}
+
+		parts = append(parts, optionNames(cliOption)[0])
+		if cliOption.ArgsRequired {
+			parts = append(parts, exampleValue(cliOption))
+		}
+	}
+
+	return parts
+}
+
+// exampleInvocation returns the shortest invocation of a scope: the required options of the program
+// and of each command on the way to it, each one after the name of the command declaring it, and
+// its required positionals.
+func exampleInvocation(scopes []commandScope, scopeIndex int) string {
+	scope := &scopes[scopeIndex]
+	parts := []string{scriptName(&scopes[0].cli)}
+
+	for depth := 0; depth <= len(scope.path); depth++ {
+		for i := range scopes {
+			if len(scopes[i].path) != depth || commandPath(&scopes[i]) != strings.Join(scope.path[:depth], " ") {
+				continue
+			}
+
+			if depth > 0 {
+				parts = append(parts, scope.path[depth-1])
+			}
+
+			parts = append(parts, requiredOptionsExample(&scopes[i].cli)...)
+		}
+	}
+
+	for i := range scope.cli.Positionals {
+		if scope.cli.Positionals[i].Required || positionalMinCount(&scope.cli.Positionals[i]) > 0 {
+			parts = append(parts, strings.TrimSpace(scope.cli.Positionals[i].Name))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// scriptExamples returns how to show the usage of the script and the shortest invocation of the
+// script, or of each of its commands when it has any.
+func scriptExamples(cli *CLIProgram) []scriptExample {
+	examples := make([]scriptExample, 0)
+
+	for i := range cli.Options {
+		if cli.Options[i].Help {
+			examples = append(examples, scriptExample{
+				description: "Show the usage of the script",
+				command:     scriptName(cli) + " " + optionNames(&cli.Options[i])[0],
+			})
+
+			break
+		}
+	}
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		if len(scopes[i].cli.Commands) > 0 {
+			continue
+		}
+
+		description := "Run the script with only its required arguments"
+		if len(scopes[i].path) > 0 {
+			description = fmt.Sprintf("Run the %s command with only its required arguments", commandPath(&scopes[i]))
+		}
+
+		examples = append(examples, scriptExample{description: description, command: exampleInvocation(scopes, i)})
+	}
+
+	return examples
+}
@@ -0,0 +1,74 @@
+package shellcligen
+
+import (
+	"testing"
+)
+
+func Test_exampleInvocation(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	cliProgram.Options = append(cliProgram.Options, CLIOption{ShortName: "c", LongName: "config", ArgsRequired: true, Required: true, Type: "file"})
+	scopes := commandScopes(&cliProgram)
+
+	type test struct {
+		scopeIndex int
+		want       string
+	}
+
+	tests := []test{
+		{scopeIndex: 1, want: "script.sh -c file deploy -e arg target"},
+		{scopeIndex: 3, want: "script.sh -c file db migrate"},
+	}
+
+	for _, tt := range tests {
+		if got := exampleInvocation(scopes, tt.scopeIndex); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_scriptExamples(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	cliProgram.Options = append(cliProgram.Options, CLIOption{ShortName: "h", LongName: "help", Help: true})
+
+	want := []scriptExample{
+		{description: "Show the usage of the script", command: "script.sh -h"},
+		{description: "Run the deploy command with only its required arguments", command: "script.sh deploy -e arg target"},
+		{description: "Run the db migrate command with only its required arguments", command: "script.sh db migrate"},
+	}
+
+	got := scriptExamples(&cliProgram)
+	if len(got) != len(want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got=%v, want=%v", got[i], want[i])
+		}
+	}
+}
+
+func Test_exampleValue(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{cliOption: CLIOption{ArgsRequired: true, Default: "it's"}, want: `'it'\''s'`},
+		{cliOption: CLIOption{ArgsRequired: true, Type: "enum", Choices: []string{"json", "yaml"}}, want: "json"},
+		{cliOption: CLIOption{ArgsRequired: true, Type: "int"}, want: "int"},
+	}
+
+	for _, tt := range tests {
+		if got := exampleValue(&tt.cliOption); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fishCompletionFileName returns the completion file name, fish autoloads the completions of a
+// command from a file named after the whole command name.
+func fishCompletionFileName(cli *CLIProgram) string {
+	return scriptName(cli) + ".fish"
+}
+
+func fishQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+	return "'" + replacer.Replace(value) + "'"
+}
+
+// fishScopeCondition returns the condition matching the command line when the scope command was
+// invoked, it is empty for the program scope.
+func fishScopeCondition(scope *commandScope) string {
+	conditions := make([]string, 0, len(scope.path))
+
+	for _, name := range scope.path {
+		conditions = append(conditions, "__fish_seen_subcommand_from "+name)
+	}
+
+	return strings.Join(conditions, "; and ")
+}
+
+// fishCommandsCondition returns the condition under which the commands of the scope are offered,
+// that is, when the scope was invoked and none of its commands was given yet.
+func fishCommandsCondition(scope *commandScope) string {
+	if len(scope.path) == 0 {
+		return "__fish_use_subcommand"
+	}
+
+	names := make([]string, 0, len(scope.cli.Commands))
+	for _, command := range scope.cli.Commands {
+		names = append(names, strings.TrimSpace(command.Name))
+	}
+
+	return fmt.Sprintf("%s; and not __fish_seen_subcommand_from %s", fishScopeCondition(scope), strings.Join(names, " "))
+}
+
+func fishArgumentFlags(cliOption *CLIOption) string {
+	switch optionType(cliOption) {
+	case enumType:
+		return fmt.Sprintf("-x -a %s", fishQuote(strings.Join(cliOption.Choices, " ")))
+	case boolType:
+		return "-x -a 'true false'"
+	case fileType, pathType:
+		return "-r -F"
+	case dirType:
+		return "-x -a '(__fish_complete_directories)'"
+	default:
+		return "-x"
+	}
+}
+
+func fishOptionConflictsCondition(program *CLIProgram, optionIndex int) []string {
+	conditions := make([]string, 0)
+
+	for _, pair := range conflictingOptionPairs(program) {
+		other := -1
+
+		switch optionIndex {
+		case pair[0]:
+			other = pair[1]
+		case pair[1]:
+			other = pair[0]
+		}
+
+		if other == -1 {
+			continue
+		}
+
+		otherOption := &program.Options[other]
+		condition := "not __fish_contains_opt"
+
+		if shortOptionName := strings.TrimSpace(otherOption.ShortName); len(shortOptionName) > 0 {
+			condition += " -s " + shortOptionName
+		}
+
+		if longOptionName := strings.TrimSpace(otherOption.LongName); len(longOptionName) > 0 {
+			condition += " " + longOptionName
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+func generateFishOption(scope *commandScope, program *CLIProgram, optionIndex int) string {
+	cliOption := &program.Options[optionIndex]
+	parts := []string{"complete", "-c", scriptName(&scope.cli)}
+
+	conditions := make([]string, 0)
+	if condition := fishScopeCondition(scope); len(condition) > 0 {
+		conditions = append(conditions, condition)
+	}
+
+	conditions = append(conditions, fishOptionConflictsCondition(program, optionIndex)...)
+
+	if len(conditions) > 0 {
+		parts = append(parts, "-n", fishQuote(strings.Join(conditions, "; and ")))
+	}
+
+	if shortOptionName := strings.TrimSpace(cliOption.ShortName); len(shortOptionName) > 0 {
+		parts = append(parts, "-s", shortOptionName)
+	}
+
+	if longOptionName := strings.TrimSpace(cliOption.LongName); len(longOptionName) > 0 {
+		parts = append(parts, "-l", longOptionName)
+	}
+
+	if cliOption.ArgsRequired {
+		parts = append(parts, fishArgumentFlags(cliOption))
+	}
+
+	if description := firstHelpLine(cliOption.Description); len(description) > 0 {
+		parts = append(parts, "-d", fishQuote(description))
+	}
+
+	return strings.Join(parts, " ") + "\n"
+}
+
+// generateFishCompletion generates the fish completions, the options of each scope are listed with
+// the scope condition so inherited options are offered by every command, as the script accepts them.
+func generateFishCompletion(cli *CLIProgram) string {
+	var completionSb strings.Builder
+
+	completionSb.WriteString(fmt.Sprintf("# Fish completion for %s, copy it to ~/.config/fish/completions.\n", scriptName(cli)))
+
+	if len(cli.Commands) > 0 {
+		completionSb.WriteString(fmt.Sprintf("\ncomplete -c %s -n '__fish_use_subcommand' -f\n", scriptName(cli)))
+	}
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		scope := &scopes[i]
+		program := scopeProgram(scope)
+
+		completionSb.WriteString("\n")
+
+		for j := len(scope.inherited); j < len(program.Options); j++ {
+			completionSb.WriteString(generateFishOption(scope, &program, j))
+		}
+
+		for _, command := range scope.cli.Commands {
+			completionSb.WriteString(fmt.Sprintf("complete -c %s -n %s -f -a %s",
+				scriptName(cli), fishQuote(fishCommandsCondition(scope)), strings.TrimSpace(command.Name)))
+
+			if description := firstHelpLine(command.Help); len(description) > 0 {
+				completionSb.WriteString(" -d " + fishQuote(description))
+			}
+
+			completionSb.WriteString("\n")
+		}
+	}
+
+	return completionSb.String()
+}
@@ -0,0 +1,98 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_fishScopeConditions(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		scope             commandScope
+		wantScope         string
+		wantCommandsScope string
+	}
+
+	tests := []test{
+		{
+			scope:             commandScope{cli: CLIProgram{Commands: []Command{{Name: "deploy"}}}},
+			wantScope:         "",
+			wantCommandsScope: "__fish_use_subcommand",
+		},
+		{
+			scope:             commandScope{path: []string{"db"}, cli: CLIProgram{Commands: []Command{{Name: "migrate"}, {Name: "seed"}}}},
+			wantScope:         "__fish_seen_subcommand_from db",
+			wantCommandsScope: "__fish_seen_subcommand_from db; and not __fish_seen_subcommand_from migrate seed",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := fishScopeCondition(&tt.scope); got != tt.wantScope {
+			t.Errorf("got=[%s], want=[%s]", got, tt.wantScope)
+		}
+
+		if got := fishCommandsCondition(&tt.scope); got != tt.wantCommandsScope {
+			t.Errorf("got=[%s], want=[%s]", got, tt.wantCommandsScope)
+		}
+	}
+}
+
+func Test_generateFishOption(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		scope       commandScope
+		optionIndex int
+		want        string
+	}
+
+	program := CLIProgram{Options: []CLIOption{
+		{ShortName: "a", LongName: "article", Description: "the article's id"},
+		{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}},
+		{ShortName: "d", LongName: "dir", ArgsRequired: true, Type: "dir"},
+	}}
+
+	tests := []test{
+		{
+			scope:       commandScope{},
+			optionIndex: 0,
+			want:        `complete -c script.sh -n 'not __fish_contains_opt -s p page' -s a -l article -d 'the article\'s id'` + "\n",
+		},
+		{
+			scope:       commandScope{path: []string{"fetch"}},
+			optionIndex: 1,
+			want:        `complete -c script.sh -n '__fish_seen_subcommand_from fetch; and not __fish_contains_opt -s a article' -s p -l page` + "\n",
+		},
+		{
+			scope:       commandScope{},
+			optionIndex: 2,
+			want:        `complete -c script.sh -s d -l dir -x -a '(__fish_complete_directories)'` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := generateFishOption(&tt.scope, &program, tt.optionIndex); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateFishCompletion(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateFishCompletion(&cliProgram)
+
+	for _, want := range []string{
+		"complete -c script.sh -n '__fish_use_subcommand' -f\n",
+		"complete -c script.sh -s v -l verbose\n",
+		"complete -c script.sh -n '__fish_use_subcommand' -f -a deploy -d 'deploy the application'\n",
+		"complete -c script.sh -n '__fish_seen_subcommand_from deploy' -s e -l env -x\n",
+		"complete -c script.sh -n '__fish_seen_subcommand_from db; and __fish_seen_subcommand_from migrate' -s s -l steps -x\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fish completion does not contain [%s]:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package shellcligen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Formats a program can be described in.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+var (
+	ErrUnknownFormat = errors.New("error unknown input format")
+
+	tomlLineRegex = regexp.MustCompile(`(?m)^(\[\[?[a-zA-Z0-9_.]+\]\]?|[a-zA-Z0-9_]+\s*=)`)
+)
+
+// DetectFormat returns the format of a program description, from the extension of its file name
+// or, when the extension is not a known one, from its content.
+func DetectFormat(fileName string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+
+	trimmed := bytes.TrimSpace(content)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	case tomlLineRegex.Match(trimmed):
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// Load reads a program description from r, its format is detected from its content. The program
+// is not validated, see CLIProgram.Validate.
+func Load(r io.Reader) (CLIProgram, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return CLIProgram{}, fmt.Errorf("error reading input file: %w", ErrReadingInputFile)
+	}
+
+	return load("", content, DetectFormat("", content))
+}
+
+// LoadFile reads a program description from a file, its format is detected from the file
+// extension or from its content. The program is not validated, see CLIProgram.Validate.
+func LoadFile(fileName string) (CLIProgram, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return CLIProgram{}, fmt.Errorf("error opening input file: %w", ErrOpeningInputFile)
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return CLIProgram{}, fmt.Errorf("error reading input file: %w", ErrReadingInputFile)
+	}
+
+	return load(fileName, content, DetectFormat(fileName, content))
+}
+
+// load decodes a program description, the problems found doing so are returned in a
+// ValidationErrors located in fileName.
+func load(fileName string, content []byte, format string) (CLIProgram, error) {
+	cli := CLIProgram{}
+
+	if err := unmarshalCLIProgram(content, format, &cli); err != nil {
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			return CLIProgram{}, fmt.Errorf("error parsing input file: %w", err)
+		}
+
+		for _, validationError := range errs {
+			validationError.File = fileName
+		}
+
+		return CLIProgram{}, errs
+	}
+
+	cli.fileName = fileName
+
+	return cli, nil
+}
+
+// unmarshalCLIProgram decodes a program description written in the given format. JSON is decoded
+// with encoding/json, reading it as YAML too only to find where things are declared.
+func unmarshalCLIProgram(content []byte, format string, cli *CLIProgram) error {
+	var errs []*ValidationError
+
+	switch format {
+	case FormatYAML:
+		errs = decodeYAML(content, cli)
+	case FormatJSON:
+		errs = decodeJSON(content, cli)
+	case FormatTOML:
+		errs = decodeTOML(content, cli)
+	default:
+		return fmt.Errorf("%s: %w", format, ErrUnknownFormat)
+	}
+
+	if len(errs) > 0 {
+		return ValidationErrors(errs)
+	}
+
+	return nil
+}
+
+// Marshal returns the program described in the given format, in the layout LoadFile reads.
+func Marshal(cli *CLIProgram, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return MarshalCLIProgram(cli)
+	case FormatJSON:
+		content, err := json.MarshalIndent(cli, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		return append(content, '\n'), nil
+	case FormatTOML:
+		var buffer bytes.Buffer
+
+		encoder := toml.NewEncoder(&buffer)
+		encoder.Indent = ""
+
+		if err := encoder.Encode(cli); err != nil {
+			return nil, err
+		}
+
+		return buffer.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%s: %w", format, ErrUnknownFormat)
+	}
+}
@@ -0,0 +1,229 @@
+package shellcligen
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	yamlTestProgram = `help_message: fetches articles
+safe_flags: true
+options:
+  - long_name: article
+    short_name: a
+    required: true
+    args_required: true
+  - long_name: page
+    short_name: p
+    conflicts_with: [a]
+commands:
+  - name: list
+    help_message: lists articles
+    positionals:
+      - name: tags
+        variadic: true
+        min_count: 1
+`
+
+	jsonTestProgram = `{
+  "help_message": "fetches articles",
+  "safe_flags": true,
+  "options": [
+    {"long_name": "article", "short_name": "a", "required": true, "args_required": true},
+    {"long_name": "page", "short_name": "p", "conflicts_with": ["a"]}
+  ],
+  "commands": [
+    {
+      "name": "list",
+      "help_message": "lists articles",
+      "positionals": [{"name": "tags", "variadic": true, "min_count": 1}]
+    }
+  ]
+}
+`
+
+	tomlTestProgram = `help_message = "fetches articles"
+safe_flags = true
+
+[[options]]
+long_name = "article"
+short_name = "a"
+required = true
+args_required = true
+
+[[options]]
+long_name = "page"
+short_name = "p"
+conflicts_with = ["a"]
+
+[[commands]]
+name = "list"
+help_message = "lists articles"
+
+[[commands.positionals]]
+name = "tags"
+variadic = true
+min_count = 1
+`
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		fileName string
+		content  string
+		want     string
+	}
+
+	tests := []test{
+		{fileName: "input.yml", content: jsonTestProgram, want: FormatYAML},
+		{fileName: "input.YAML", content: "", want: FormatYAML},
+		{fileName: "input.json", content: "", want: FormatJSON},
+		{fileName: "input.toml", content: "", want: FormatTOML},
+		{fileName: "input", content: jsonTestProgram, want: FormatJSON},
+		{fileName: "input.spec", content: tomlTestProgram, want: FormatTOML},
+		{fileName: "-", content: yamlTestProgram, want: FormatYAML},
+		{fileName: "", content: "help_message: |\n  a = b\n", want: FormatYAML},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.fileName, []byte(tt.content)); got != tt.want {
+			t.Errorf("got=%s, want=%s for [%s]", got, tt.want, tt.fileName)
+		}
+	}
+}
+
+func Test_unmarshalCLIProgram(t *testing.T) {
+	t.Parallel()
+
+	want := CLIProgram{
+		Help:      "fetches articles",
+		SafeFlags: true,
+		Options: []CLIOption{
+			{LongName: "article", ShortName: "a", Required: true, ArgsRequired: true},
+			{LongName: "page", ShortName: "p", ConflictsWith: []string{"a"}},
+		},
+		Commands: []Command{
+			{
+				Name:        "list",
+				Help:        "lists articles",
+				Positionals: []Positional{{Name: "tags", Variadic: true, MinCount: 1}},
+			},
+		},
+	}
+
+	type test struct {
+		content string
+		format  string
+	}
+
+	tests := []test{
+		{content: yamlTestProgram, format: FormatYAML},
+		{content: jsonTestProgram, format: FormatJSON},
+		{content: tomlTestProgram, format: FormatTOML},
+	}
+
+	for _, tt := range tests {
+		got := CLIProgram{}
+		if err := unmarshalCLIProgram([]byte(tt.content), tt.format, &got); err != nil {
+			t.Errorf("got=%v, want no error for %s", err, tt.format)
+
+			continue
+		}
+
+		clearPositions(&got.Options, &got.Positionals, &got.Commands)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got=%+v, want=%+v for %s", got, want, tt.format)
+		}
+	}
+
+	if err := unmarshalCLIProgram([]byte(yamlTestProgram), "xml", &CLIProgram{}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("got=%v, want=%v", err, ErrUnknownFormat)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	for _, content := range []string{yamlTestProgram, jsonTestProgram, tomlTestProgram} {
+		cli, err := Load(strings.NewReader(content))
+		if err != nil {
+			t.Errorf("got=%v, want no error", err)
+
+			continue
+		}
+
+		if cli.Help != "fetches articles" || len(cli.Options) != 2 || len(cli.Commands) != 1 {
+			t.Errorf("got=%+v, want the test program", cli)
+		}
+	}
+
+	if _, err := Load(strings.NewReader("{ not json")); err == nil {
+		t.Errorf("got no error, want a parsing error")
+	}
+
+	cli, err := Load(strings.NewReader(`{"help_message": "fetches articles\/pages"}`))
+	if err != nil || cli.Help != "fetches articles/pages" {
+		t.Errorf("got=%v %q, want the escaped JSON string decoded", err, cli.Help)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	want := commandsTestProgram()
+
+	for _, format := range []string{FormatYAML, FormatJSON, FormatTOML} {
+		content, err := Marshal(&want, format)
+		if err != nil {
+			t.Errorf("got=%v, want no error for %s", err, format)
+
+			continue
+		}
+
+		got := CLIProgram{}
+		if err := unmarshalCLIProgram(content, format, &got); err != nil {
+			t.Errorf("got=%v, want no error reading back %s", err, format)
+
+			continue
+		}
+
+		clearPositions(&got.Options, &got.Positionals, &got.Commands)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got=%+v, want=%+v for %s", got, want, format)
+		}
+	}
+
+	if _, err := Marshal(&want, "xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("got=%v, want=%v", err, ErrUnknownFormat)
+	}
+}
+
+func TestCLIProgram_Validate(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cli  CLIProgram
+		want error
+	}
+
+	tests := []test{
+		{cli: commandsTestProgram(), want: nil},
+		{cli: CLIProgram{Options: []CLIOption{{ShortName: "a", LongName: "all", Type: "bytes"}}}, want: ErrInvalidOptionType},
+		{cli: CLIProgram{Commands: []Command{{Name: "a b"}}}, want: ErrInvalidCommand},
+		{cli: CLIProgram{Name: "fetch-articles.sh"}, want: nil},
+		{cli: CLIProgram{Name: "../fetch"}, want: ErrInvalidProgramName},
+		{cli: CLIProgram{Name: "-fetch"}, want: ErrInvalidProgramName},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cli.Validate(); !errors.Is(got, tt.want) {
+			t.Errorf("got=%v, want=%v", got, tt.want)
+		}
+	}
+}
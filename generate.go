@@ -0,0 +1,282 @@
+package shellcligen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Shells completion scripts are generated for.
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+)
+
+var (
+	ErrUnknownShell        = errors.New("error unknown shell")
+	ErrRepeatedOutputFiles = errors.New("error repeated output file names")
+)
+
+// GeneratedFile is a file generated for a program, Name is relative to the output directory.
+type GeneratedFile struct {
+	Name    string
+	Content string
+	Mode    os.FileMode
+}
+
+// scriptName returns the file name of the script of the program.
+func scriptName(cli *CLIProgram) string {
+	if name := strings.TrimSpace(cli.Name); len(name) > 0 {
+		return name
+	}
+
+	return scriptFileName
+}
+
+// configFileName returns the file name of the configuration file of the script.
+func configFileName(cli *CLIProgram) string {
+	if name := strings.TrimSpace(cli.Name); len(name) > 0 {
+		return name + ".conf"
+	}
+
+	return scriptConfigFileName
+}
+
+// Generator generates the files of programs. Its zero value renders scripts with the built-in
+// template.
+type Generator struct {
+	// ScriptTemplate renders the script instead of the built-in template, see ParseScriptTemplate.
+	ScriptTemplate *template.Template
+
+	// Force overwrites files changed since they were generated.
+	Force bool
+
+	// MakeDirectory creates the output directory, and its parents, when it does not exist.
+	MakeDirectory bool
+}
+
+func (g *Generator) scriptTemplate() *template.Template {
+	if g.ScriptTemplate == nil {
+		return builtinScriptTemplate
+	}
+
+	return g.ScriptTemplate
+}
+
+// Generate writes the script of the program to w. The program is expected to be valid, see
+// CLIProgram.Validate.
+func (g *Generator) Generate(cli *CLIProgram, w io.Writer) error {
+	script, err := renderScript(g.scriptTemplate(), cli)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, script)
+
+	return err
+}
+
+// GenerateFiles returns every file generated for the program, the script first, without writing
+// anything to disk. Each file has a header recording its checksum and the script is executable.
+// The program is expected to be valid, see CLIProgram.Validate. Names making two files share a
+// file name, like tool.md whose documentation is tool.md too, are rejected.
+func (g *Generator) GenerateFiles(cli *CLIProgram) ([]GeneratedFile, error) {
+	script, err := renderScript(g.scriptTemplate(), cli)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []GeneratedFile{
+		{Name: scriptName(cli), Content: script, Mode: 0o755},
+		{Name: configFileName(cli), Content: generateConfigFile(cli)},
+		{Name: bashCompletionFileName(cli), Content: generateBashCompletion(cli)},
+		{Name: zshCompletionFileName(cli), Content: generateZshCompletion(cli)},
+		{Name: fishCompletionFileName(cli), Content: generateFishCompletion(cli)},
+		{Name: manPageFileName(cli), Content: generateManPage(cli)},
+		{Name: markdownFileName(cli), Content: generateMarkdown(cli)},
+	}
+
+	generated := make(map[string]bool)
+
+	for i := range files {
+		if generated[files[i].Name] {
+			return nil, fmt.Errorf("%s: %w, rename the script", files[i].Name, ErrRepeatedOutputFiles)
+		}
+
+		generated[files[i].Name] = true
+		files[i].Content = withGeneratedHeader(files[i].Name, files[i].Content)
+
+		if files[i].Mode == 0 {
+			files[i].Mode = 0o644
+		}
+	}
+
+	return files, nil
+}
+
+// GenerateDir writes the files generated for the program to outputDirectory, returning the files
+// written. When the script already exists, the code of its user code regions is kept. The
+// configuration file belongs to the user once written, it is only written when it does not exist.
+// Other existing files changed since they were generated are not overwritten unless Force is set,
+// and the files are written to temporary files first, renamed once all of them are written.
+func (g *Generator) GenerateDir(cli *CLIProgram, outputDirectory string) ([]GeneratedFile, error) {
+	files, err := g.GenerateFiles(cli)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.MakeDirectory {
+		if err := os.MkdirAll(outputDirectory, 0o755); err != nil {
+			return nil, fmt.Errorf("error creating %s: %w", outputDirectory, err)
+		}
+	}
+
+	written := make([]GeneratedFile, 0, len(files))
+
+	for i := range files {
+		existing, err := os.ReadFile(path.Join(outputDirectory, files[i].Name))
+		if errors.Is(err, os.ErrNotExist) {
+			written = append(written, files[i])
+
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", files[i].Name, err)
+		}
+
+		if files[i].Name == configFileName(cli) {
+			continue
+		}
+
+		if i == 0 {
+			if files[i].Content, err = mergeUserCode(files[i].Content, string(existing)); err != nil {
+				return nil, fmt.Errorf("%s: %w", files[i].Name, err)
+			}
+		}
+
+		if err := checkUnmodified(string(existing)); err != nil && !g.Force {
+			return nil, fmt.Errorf("%s: %w, use force to overwrite it", files[i].Name, err)
+		}
+
+		written = append(written, files[i])
+	}
+
+	return writeFiles(written, outputDirectory)
+}
+
+// writeFiles writes every file to a temporary file of outputDirectory and renames them once they are
+// all written, so an error writing them leaves the existing files as they were.
+func writeFiles(files []GeneratedFile, outputDirectory string) ([]GeneratedFile, error) {
+	temporaryFiles := make([]string, 0, len(files))
+
+	defer func() {
+		for _, temporaryFile := range temporaryFiles {
+			_ = os.Remove(temporaryFile)
+		}
+	}()
+
+	for _, file := range files {
+		temporaryFile, err := writeTemporaryFile(file, outputDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("error writing %s: %w", file.Name, err)
+		}
+
+		temporaryFiles = append(temporaryFiles, temporaryFile)
+	}
+
+	for i, file := range files {
+		if err := os.Rename(temporaryFiles[i], path.Join(outputDirectory, file.Name)); err != nil {
+			temporaryFiles = temporaryFiles[i:]
+
+			return files[:i], fmt.Errorf("error writing %s: %w", file.Name, err)
+		}
+	}
+
+	temporaryFiles = nil
+
+	return files, nil
+}
+
+// writeTemporaryFile writes the file to a temporary file of outputDirectory, returning its path.
+func writeTemporaryFile(file GeneratedFile, outputDirectory string) (string, error) {
+	temporaryFile, err := os.CreateTemp(outputDirectory, "."+file.Name+".*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = temporaryFile.WriteString(file.Content)
+	if err == nil {
+		err = temporaryFile.Chmod(file.Mode)
+	}
+
+	if closeErr := temporaryFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(temporaryFile.Name())
+
+		return "", err
+	}
+
+	return temporaryFile.Name(), nil
+}
+
+// Generate writes the script of the program to w with the built-in template. The program is
+// expected to be valid, see CLIProgram.Validate.
+func Generate(cli *CLIProgram, w io.Writer) error {
+	return (&Generator{}).Generate(cli, w)
+}
+
+// GenerateCompletion writes the completion script of the program for shell, one of the Shell
+// constants, to w.
+func GenerateCompletion(cli *CLIProgram, shell string, w io.Writer) error {
+	var completion string
+
+	switch shell {
+	case ShellBash:
+		completion = generateBashCompletion(cli)
+	case ShellZsh:
+		completion = generateZshCompletion(cli)
+	case ShellFish:
+		completion = generateFishCompletion(cli)
+	default:
+		return fmt.Errorf("%s: %w", shell, ErrUnknownShell)
+	}
+
+	_, err := io.WriteString(w, completion)
+
+	return err
+}
+
+// GenerateManPage writes the man page of the program to w.
+func GenerateManPage(cli *CLIProgram, w io.Writer) error {
+	_, err := io.WriteString(w, generateManPage(cli))
+
+	return err
+}
+
+// GenerateMarkdown writes the Markdown documentation of the program to w.
+func GenerateMarkdown(cli *CLIProgram, w io.Writer) error {
+	_, err := io.WriteString(w, generateMarkdown(cli))
+
+	return err
+}
+
+// GenerateFiles returns every file generated for the program with the built-in template, see
+// Generator.GenerateFiles.
+func GenerateFiles(cli *CLIProgram) ([]GeneratedFile, error) {
+	return (&Generator{}).GenerateFiles(cli)
+}
+
+// GenerateDir writes the files generated for the program with the built-in template to
+// outputDirectory, see Generator.GenerateDir.
+func GenerateDir(cli *CLIProgram, outputDirectory string) ([]GeneratedFile, error) {
+	return (&Generator{}).GenerateDir(cli, outputDirectory)
+}
@@ -0,0 +1,254 @@
+package shellcligen
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+
+	var script bytes.Buffer
+	if err := Generate(&cliProgram, &script); err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	if got, want := script.String(), renderTestScript(t, &cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func TestGenerateCompletion(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+
+	type test struct {
+		shell string
+		want  string
+	}
+
+	tests := []test{
+		{shell: ShellBash, want: generateBashCompletion(&cliProgram)},
+		{shell: ShellZsh, want: generateZshCompletion(&cliProgram)},
+		{shell: ShellFish, want: generateFishCompletion(&cliProgram)},
+	}
+
+	for _, tt := range tests {
+		var completion bytes.Buffer
+		if err := GenerateCompletion(&cliProgram, tt.shell, &completion); err != nil || completion.String() != tt.want {
+			t.Errorf("got=%v, want the %s completion", err, tt.shell)
+		}
+	}
+
+	if err := GenerateCompletion(&cliProgram, "csh", &bytes.Buffer{}); !errors.Is(err, ErrUnknownShell) {
+		t.Errorf("got=%v, want=%v", err, ErrUnknownShell)
+	}
+}
+
+func TestGenerateFiles(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+
+	files, err := GenerateFiles(&cliProgram)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+
+		if len(file.Content) == 0 {
+			t.Errorf("got an empty %s, want content", file.Name)
+		}
+	}
+
+	if got, want := strings.Join(names, " "), "script.sh script.conf script-completion.bash _script.sh script.sh.fish script.sh.1 script.md"; got != want {
+		t.Errorf("got=%s, want=%s", got, want)
+	}
+
+	if files[0].Mode != 0o755 || files[1].Mode != 0o644 {
+		t.Errorf("got=%v %v, want the script executable", files[0].Mode, files[1].Mode)
+	}
+
+	cliProgram.Name = "fetch"
+
+	files, err = GenerateFiles(&cliProgram)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	names = names[:0]
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+
+	if got, want := strings.Join(names, " "), "fetch fetch.conf fetch-completion.bash _fetch fetch.fish fetch.1 fetch.md"; got != want {
+		t.Errorf("got=%s, want=%s", got, want)
+	}
+
+	if !strings.Contains(files[0].Content, `load_config "$(dirname "${BASH_SOURCE[0]}")/fetch.conf"`) {
+		t.Errorf("got=[%s], want the script to load fetch.conf", files[0].Content)
+	}
+
+	cliProgram.Name = "fetch.md"
+
+	if _, err := GenerateFiles(&cliProgram); !errors.Is(err, ErrRepeatedOutputFiles) {
+		t.Errorf("got=%v, want=%v", err, ErrRepeatedOutputFiles)
+	}
+}
+
+func TestParseCLIProgram(t *testing.T) {
+	t.Parallel()
+
+	inputDirectory, outputDirectory := t.TempDir(), t.TempDir()
+	inputFile := path.Join(inputDirectory, "input.toml")
+
+	if err := os.WriteFile(inputFile, []byte(tomlTestProgram), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cli, err := ParseCLIProgram(inputFile, outputDirectory)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	files, err := GenerateFiles(&cli)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(path.Join(outputDirectory, file.Name))
+		if err != nil || string(content) != file.Content {
+			t.Errorf("got=%v, want %s to be generated", err, file.Name)
+		}
+	}
+
+	markdownFile := files[len(files)-1].Name
+	if err := os.WriteFile(path.Join(outputDirectory, markdownFile), []byte("# fetch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseCLIProgram(inputFile, outputDirectory); !errors.Is(err, ErrCreatingOutputProgram) ||
+		!errors.Is(err, ErrModifiedOutput) || !strings.Contains(err.Error(), markdownFile) {
+		t.Errorf("got=%v, want=%v naming %s", err, ErrModifiedOutput, markdownFile)
+	}
+
+	if _, err := ParseCLIProgram(path.Join(inputDirectory, "missing.yml"), outputDirectory); !errors.Is(err, ErrOpeningInputFile) {
+		t.Errorf("got=%v, want=%v", err, ErrOpeningInputFile)
+	}
+
+	invalidFile := path.Join(inputDirectory, "invalid.yml")
+	if err := os.WriteFile(invalidFile, []byte("options:\n  - short_name: ab\n    long_name: ab\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseCLIProgram(invalidFile, outputDirectory); !errors.Is(err, ErrInvalidOptionName) {
+		t.Errorf("got=%v, want=%v", err, ErrInvalidOptionName)
+	}
+}
+
+func TestGenerateDir(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	outputDirectory := t.TempDir()
+
+	files, err := GenerateDir(&cliProgram, outputDirectory)
+	wantFiles, _ := GenerateFiles(&cliProgram)
+
+	if err != nil || !reflect.DeepEqual(files, wantFiles) {
+		t.Fatalf("got=%v, want every file written", err)
+	}
+
+	if _, err := GenerateDir(&cliProgram, path.Join(outputDirectory, "missing")); err == nil {
+		t.Errorf("got no error, want an error writing to a missing directory")
+	}
+
+	scriptFile := path.Join(outputDirectory, scriptFileName)
+	userCode := "# BEGIN USER CODE main\nfetch_articles \"${@}\"\n# END USER CODE\n"
+	script := strings.Replace(files[0].Content, "# BEGIN USER CODE main\n# END USER CODE\n", userCode, 1)
+
+	if err := os.WriteFile(scriptFile, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GenerateDir(&cliProgram, outputDirectory); err != nil {
+		t.Fatalf("got=%v, want no error generating again", err)
+	}
+
+	if got, _ := os.ReadFile(scriptFile); string(got) != script {
+		t.Errorf("got=[%s], want the user code kept", got)
+	}
+
+	if err := os.WriteFile(scriptFile, []byte(strings.Replace(script, "# END USER CODE\n", "", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GenerateDir(&cliProgram, outputDirectory); !errors.Is(err, ErrUserCodeRegion) {
+		t.Errorf("got=%v, want=%v", err, ErrUserCodeRegion)
+	}
+
+	configFile := path.Join(outputDirectory, scriptConfigFileName)
+	if err := os.WriteFile(scriptFile, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(configFile, []byte(files[1].Content+"article=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := GenerateDir(&cliProgram, outputDirectory)
+	if err != nil || len(written) != len(files)-1 {
+		t.Errorf("got=%v, want every file but the configuration file written", err)
+	}
+
+	if _, err := (&Generator{Force: true}).GenerateDir(&cliProgram, outputDirectory); err != nil {
+		t.Errorf("got=%v, want no error forcing", err)
+	}
+
+	if got, _ := os.ReadFile(configFile); string(got) != files[1].Content+"article=1\n" {
+		t.Errorf("got=[%s], want the edited configuration file kept", got)
+	}
+
+	manPageFile := path.Join(outputDirectory, files[5].Name)
+	if err := os.WriteFile(manPageFile, []byte(".TH SCRIPT.SH 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GenerateDir(&cliProgram, outputDirectory); !errors.Is(err, ErrModifiedOutput) {
+		t.Errorf("got=%v, want=%v", err, ErrModifiedOutput)
+	}
+
+	if _, err := (&Generator{Force: true}).GenerateDir(&cliProgram, outputDirectory); err != nil {
+		t.Errorf("got=%v, want no error forcing", err)
+	}
+
+	if got, _ := os.ReadFile(manPageFile); string(got) != files[5].Content {
+		t.Errorf("got=[%s], want=[%s]", got, files[5].Content)
+	}
+
+	entries, _ := os.ReadDir(outputDirectory)
+	if len(entries) != len(files) {
+		t.Errorf("got=%d, want=%d files, no temporary file left", len(entries), len(files))
+	}
+
+	if info, err := os.Stat(scriptFile); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("got=%v, want the script executable", err)
+	}
+
+	missingDirectory := path.Join(outputDirectory, "bin", "tools")
+	if _, err := (&Generator{MakeDirectory: true}).GenerateDir(&cliProgram, missingDirectory); err != nil {
+		t.Errorf("got=%v, want the output directory created", err)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/leogtzr/shellcligen
 
 go 1.16
 
-require gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+require (
+	github.com/BurntSushi/toml v1.3.2
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
+)
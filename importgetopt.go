@@ -0,0 +1,380 @@
+package shellcligen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrMissingGetoptCall = errors.New("error missing getopt call")
+
+	getoptCallRegex = regexp.MustCompile(`(^|[^a-zA-Z0-9_])getopt[ \t]`)
+	caseArmRegex    = regexp.MustCompile(`^\s*\(?\s*(-[^\s|)]+(\s*\|\s*-[^\s|)]+)*)\s*\)`)
+	optionNameRegex = regexp.MustCompile(`^(-[a-zA-Z0-9_]|--[a-zA-Z0-9_][a-zA-Z0-9_-]*)$`)
+	helpExitRegex   = regexp.MustCompile(`(?m)\bexit(\s+0)?\s*(;|$)`)
+	safeFlagsRegex  = regexp.MustCompile(`(?m)^\s*set\s+(-o\s+errexit|-[a-zA-Z]*e[a-zA-Z]*)\b`)
+)
+
+// getoptSpec is an option as declared in the getopt call.
+type getoptSpec struct {
+	name         string
+	argsRequired bool
+}
+
+// caseArm is an arm of the case statement handling the options parsed by getopt.
+type caseArm struct {
+	names []string
+	body  string
+}
+
+// joinContinuationLines joins the lines ending with a backslash with the next one.
+func joinContinuationLines(script string) string {
+	return strings.ReplaceAll(script, "\\\n", " ")
+}
+
+// shellWords splits a shell command line into words, handling quotes, up to the first unquoted
+// character ending the command.
+func shellWords(line string) []string {
+	words := make([]string, 0)
+
+	var wordSb strings.Builder
+
+	inWord, quote := false, rune(0)
+
+	for _, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			wordSb.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case strings.ContainsRune(" \t", c):
+			if inWord {
+				words = append(words, wordSb.String())
+				wordSb.Reset()
+			}
+
+			inWord = false
+		case strings.ContainsRune(")|;&<>\n", c):
+			if inWord {
+				words = append(words, wordSb.String())
+			}
+
+			return words
+		default:
+			wordSb.WriteRune(c)
+
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, wordSb.String())
+	}
+
+	return words
+}
+
+// parseGetoptSpecs parses the option specs given to getopt, like `a:,f,h` or `abc:,help,flag`.
+// Commas separate long options and are tolerated between short ones.
+func parseGetoptSpecs(specs string, long bool) ([]getoptSpec, []string) {
+	options := make([]getoptSpec, 0)
+	warnings := make([]string, 0)
+
+	specs = strings.TrimLeft(specs, "+-")
+
+	for _, spec := range strings.Split(specs, ",") {
+		for len(spec) > 0 {
+			name := spec[:1]
+			if long {
+				name = strings.TrimRight(spec, ":")
+			}
+
+			spec = spec[len(name):]
+			colons := len(spec) - len(strings.TrimLeft(spec, ":"))
+			spec = spec[colons:]
+
+			if colons > 1 {
+				warnings = append(warnings, fmt.Sprintf("option %s takes an optional argument, imported as requiring one", name))
+			}
+
+			options = append(options, getoptSpec{name: name, argsRequired: colons > 0})
+		}
+	}
+
+	return options, warnings
+}
+
+// findGetoptCall returns the words of the first getopt call of the script, starting after getopt.
+func findGetoptCall(script string) ([]string, bool) {
+	for _, line := range strings.Split(joinContinuationLines(script), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		location := getoptCallRegex.FindStringIndex(line)
+		if location == nil {
+			continue
+		}
+
+		return shellWords(line[location[1]:]), true
+	}
+
+	return nil, false
+}
+
+// parseGetoptCall returns the short and long option specs of a getopt call. Without -o, the first
+// word which is not an option holds the short options, as in the `getopt ab:c "${@}"` form.
+func parseGetoptCall(words []string) (shortSpecs, longSpecs []string) {
+	optionsGiven := false
+
+	for _, word := range words {
+		if word == "-o" || word == "--options" || strings.HasPrefix(word, "--options=") ||
+			(strings.HasPrefix(word, "-o") && !strings.HasPrefix(word, "--")) {
+			optionsGiven = true
+		}
+	}
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		value := ""
+		if i+1 < len(words) {
+			value = words[i+1]
+		}
+
+		switch {
+		case word == "--":
+			return shortSpecs, longSpecs
+		case word == "-o" || word == "--options":
+			shortSpecs = append(shortSpecs, value)
+			i++
+		case word == "-l" || word == "--long" || word == "--longoptions":
+			longSpecs = append(longSpecs, value)
+			i++
+		case word == "-n" || word == "--name" || word == "-s" || word == "--shell":
+			i++
+		case strings.HasPrefix(word, "--options="):
+			shortSpecs = append(shortSpecs, strings.TrimPrefix(word, "--options="))
+		case strings.HasPrefix(word, "--longoptions=") || strings.HasPrefix(word, "--long="):
+			longSpecs = append(longSpecs, word[strings.Index(word, "=")+1:])
+		case strings.HasPrefix(word, "-o"):
+			shortSpecs = append(shortSpecs, strings.TrimPrefix(word, "-o"))
+		case strings.HasPrefix(word, "-l"):
+			longSpecs = append(longSpecs, strings.TrimPrefix(word, "-l"))
+		case !optionsGiven && !strings.HasPrefix(word, "-"):
+			return append(shortSpecs, word), longSpecs
+		}
+	}
+
+	return shortSpecs, longSpecs
+}
+
+// parseCaseArms returns the case arms matching options, like `-a|--abc)`, along with their body.
+func parseCaseArms(script string) []caseArm {
+	arms := make([]caseArm, 0)
+	lines := strings.Split(script, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		match := caseArmRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+
+		arm := caseArm{}
+		for _, name := range strings.Split(match[1], "|") {
+			if name = strings.TrimSpace(name); optionNameRegex.MatchString(name) {
+				arm.names = append(arm.names, name)
+			}
+		}
+
+		var bodySb strings.Builder
+
+		body := lines[i][len(match[0]):]
+		for ; ; body = lines[i] {
+			if end := strings.Index(body, ";;"); end != -1 {
+				bodySb.WriteString(body[:end])
+
+				break
+			}
+
+			bodySb.WriteString(body + "\n")
+
+			if i+1 == len(lines) {
+				break
+			}
+
+			i++
+		}
+
+		arm.body = bodySb.String()
+
+		if len(arm.names) > 0 {
+			arms = append(arms, arm)
+		}
+	}
+
+	return arms
+}
+
+// isHelpCaseArm tells whether the arm prints the usage and exits successfully.
+func isHelpCaseArm(arm *caseArm) bool {
+	return (strings.Contains(arm.body, "usage") || strings.Contains(arm.body, "help")) && helpExitRegex.MatchString(arm.body)
+}
+
+func findCaseArm(arms []caseArm, name string) *caseArm {
+	for i := range arms {
+		for _, armName := range arms[i].names {
+			if armName == name {
+				return &arms[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// pairedLongOption returns the index of the long option matching a short one: the one sharing its
+// case arm or, failing that, the first one starting with the same letter, taking an argument alike
+// and handled the same way.
+func pairedLongOption(short getoptSpec, longOptions []getoptSpec, paired []bool, arms []caseArm) int {
+	shortArm := findCaseArm(arms, "-"+short.name)
+
+	for i := range longOptions {
+		if !paired[i] && shortArm != nil && findCaseArm(arms, "--"+longOptions[i].name) == shortArm {
+			return i
+		}
+	}
+
+	if shortArm != nil && len(shortArm.names) > 1 {
+		return -1
+	}
+
+	for i := range longOptions {
+		if paired[i] || !strings.HasPrefix(longOptions[i].name, short.name) || longOptions[i].argsRequired != short.argsRequired {
+			continue
+		}
+
+		longArm := findCaseArm(arms, "--"+longOptions[i].name)
+		if (shortArm == nil && longArm == nil) ||
+			(shortArm != nil && longArm != nil && len(longArm.names) == 1 &&
+				strings.TrimSpace(shortArm.body) == strings.TrimSpace(longArm.body)) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isHandledByCaseArm(cliOption *CLIOption, arms []caseArm) bool {
+	for _, name := range optionNames(cliOption) {
+		if findCaseArm(arms, name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHelpOption(cliOption *CLIOption, arms []caseArm) bool {
+	if cliOption.LongName == "help" {
+		return true
+	}
+
+	for _, name := range optionNames(cliOption) {
+		if arm := findCaseArm(arms, name); arm != nil && isHelpCaseArm(arm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importedOptionWarning returns why an imported option needs to be edited before generating a
+// script from it, or an empty string when it can be used as is.
+func importedOptionWarning(cliOption *CLIOption) string {
+	switch {
+	case len(cliOption.ShortName) == 0 || len(cliOption.LongName) == 0:
+		return fmt.Sprintf("option %s needs both a short and a long name", displayOptionName(cliOption))
+	case !isOptionNameValid(cliOption.LongName, cliOptionRegex):
+		return fmt.Sprintf("option %s has an invalid long name", displayOptionName(cliOption))
+	default:
+		return ""
+	}
+}
+
+// ImportGetopt builds a program from a script parsing its options with getopt. Short and long
+// options handled by the same case arm become a single option, options a program can not be
+// generated from as is are reported in the returned warnings.
+func ImportGetopt(script string) (CLIProgram, []string, error) {
+	words, found := findGetoptCall(script)
+	if !found {
+		return CLIProgram{}, nil, fmt.Errorf("error importing script: %w", ErrMissingGetoptCall)
+	}
+
+	shortSpecs, longSpecs := parseGetoptCall(words)
+	shortOptions, warnings := parseGetoptSpecs(strings.Join(shortSpecs, ","), false)
+	longOptions, longWarnings := parseGetoptSpecs(strings.Join(longSpecs, ","), true)
+	warnings = append(warnings, longWarnings...)
+
+	arms := parseCaseArms(script)
+	paired := make([]bool, len(longOptions))
+	cli := CLIProgram{SafeFlags: safeFlagsRegex.MatchString(script)}
+
+	for _, short := range shortOptions {
+		cliOption := CLIOption{ShortName: short.name, ArgsRequired: short.argsRequired}
+
+		if i := pairedLongOption(short, longOptions, paired, arms); i != -1 {
+			paired[i] = true
+			cliOption.LongName = longOptions[i].name
+			cliOption.ArgsRequired = cliOption.ArgsRequired || longOptions[i].argsRequired
+		}
+
+		cli.Options = append(cli.Options, cliOption)
+	}
+
+	for i := range longOptions {
+		if !paired[i] {
+			cli.Options = append(cli.Options, CLIOption{LongName: longOptions[i].name, ArgsRequired: longOptions[i].argsRequired})
+		}
+	}
+
+	for i := range cli.Options {
+		cliOption := &cli.Options[i]
+		cliOption.Help = isHelpOption(cliOption, arms)
+
+		if warning := importedOptionWarning(cliOption); len(warning) > 0 {
+			warnings = append(warnings, warning)
+		}
+
+		if len(arms) > 0 && !isHandledByCaseArm(cliOption, arms) {
+			warnings = append(warnings, fmt.Sprintf("option %s is not handled by any case arm", displayOptionName(cliOption)))
+		}
+	}
+
+	return cli, warnings, nil
+}
+
+// MarshalCLIProgram returns the program as YAML, in the format the program is read from.
+func MarshalCLIProgram(cli *CLIProgram) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(cli); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,231 @@
+package shellcligen
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const legacyGetoptScript = `#!/bin/bash
+set -o errexit
+
+# opts=$(getopt --options z -- "${@}")
+opts=$(getopt --options a:,f,h,x:: --long abc:,help,flag,dry-run \
+    -n "$(basename "${0}")" -- "${@}") || { usage; exit 2; }
+eval set -- "${opts}"
+
+while true; do
+    case "${1}" in
+    -a|--abc)
+        abc="${2}"
+        shift 2
+        ;;
+    -h | --help)
+        usage
+        exit 0
+        ;;
+    -f) flag=1; shift ;;
+    --flag) flag=1; shift ;;
+    --dry-run) dry_run=1; shift ;;
+    --)
+        shift
+        break
+        ;;
+    *) exit 2 ;;
+    esac
+done
+`
+
+func Test_shellWords(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		line string
+		want []string
+	}
+
+	tests := []test{
+		{line: `--options a:,f,h --long abc:,help,flag -- "${args[@]}" 2> /dev/null)`, want: []string{"--options", "a:,f,h", "--long", "abc:,help,flag", "--", "${args[@]}", "2"}},
+		{line: `-o 'a:b' -n "my script")`, want: []string{"-o", "a:b", "-n", "my script"}},
+		{line: `ab:c $*` + "`", want: []string{"ab:c", "$*`"}},
+	}
+
+	for _, tt := range tests {
+		if got := shellWords(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got=%q, want=%q", got, tt.want)
+		}
+	}
+}
+
+func Test_parseGetoptSpecs(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		specs        string
+		long         bool
+		want         []getoptSpec
+		wantWarnings int
+	}
+
+	tests := []test{
+		{
+			specs: "a:,f,h",
+			want:  []getoptSpec{{name: "a", argsRequired: true}, {name: "f"}, {name: "h"}},
+		},
+		{
+			specs:        "+ab:c::",
+			want:         []getoptSpec{{name: "a"}, {name: "b", argsRequired: true}, {name: "c", argsRequired: true}},
+			wantWarnings: 1,
+		},
+		{
+			specs: "abc:,help,flag",
+			long:  true,
+			want:  []getoptSpec{{name: "abc", argsRequired: true}, {name: "help"}, {name: "flag"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, warnings := parseGetoptSpecs(tt.specs, tt.long)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got=%v, want=%v", got, tt.want)
+		}
+
+		if len(warnings) != tt.wantWarnings {
+			t.Errorf("got=%v, want=%d warnings", warnings, tt.wantWarnings)
+		}
+	}
+}
+
+func Test_parseGetoptCall(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		words     []string
+		wantShort []string
+		wantLong  []string
+	}
+
+	tests := []test{
+		{
+			words:     []string{"--options", "a:,f,h", "--long", "abc:,help,flag", "--", "${args[@]}"},
+			wantShort: []string{"a:,f,h"},
+			wantLong:  []string{"abc:,help,flag"},
+		},
+		{
+			words:     []string{"-n", "tool", "-oab:", "--longoptions=all,bee:", "-l", "cee", "--", "${@}"},
+			wantShort: []string{"ab:"},
+			wantLong:  []string{"all,bee:", "cee"},
+		},
+		{
+			words:     []string{"ab:c", "$*"},
+			wantShort: []string{"ab:c"},
+		},
+	}
+
+	for _, tt := range tests {
+		gotShort, gotLong := parseGetoptCall(tt.words)
+		if !reflect.DeepEqual(gotShort, tt.wantShort) || !reflect.DeepEqual(gotLong, tt.wantLong) {
+			t.Errorf("got=%q %q, want=%q %q", gotShort, gotLong, tt.wantShort, tt.wantLong)
+		}
+	}
+}
+
+func Test_parseCaseArms(t *testing.T) {
+	t.Parallel()
+
+	arms := parseCaseArms(legacyGetoptScript)
+
+	wantNames := [][]string{{"-a", "--abc"}, {"-h", "--help"}, {"-f"}, {"--flag"}, {"--dry-run"}}
+	if len(arms) != len(wantNames) {
+		t.Fatalf("got=%v, want=%v", arms, wantNames)
+	}
+
+	for i := range wantNames {
+		if !reflect.DeepEqual(arms[i].names, wantNames[i]) {
+			t.Errorf("got=%v, want=%v", arms[i].names, wantNames[i])
+		}
+	}
+
+	if !isHelpCaseArm(&arms[1]) || isHelpCaseArm(&arms[0]) {
+		t.Errorf("got help arms=%t,%t, want=true,false", isHelpCaseArm(&arms[1]), isHelpCaseArm(&arms[0]))
+	}
+}
+
+func TestImportGetopt(t *testing.T) {
+	t.Parallel()
+
+	cli, warnings, err := ImportGetopt(legacyGetoptScript)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	want := CLIProgram{
+		SafeFlags: true,
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "abc", ArgsRequired: true},
+			{ShortName: "f", LongName: "flag"},
+			{ShortName: "h", LongName: "help", Help: true},
+			{ShortName: "x", ArgsRequired: true},
+			{LongName: "dry-run"},
+		},
+	}
+
+	if !reflect.DeepEqual(cli, want) {
+		t.Errorf("got=%+v, want=%+v", cli, want)
+	}
+
+	for _, want := range []string{
+		"option x takes an optional argument, imported as requiring one",
+		"option -x needs both a short and a long name",
+		"option -x is not handled by any case arm",
+		"option --dry-run needs both a short and a long name",
+	} {
+		if !strings.Contains(strings.Join(warnings, "\n"), want) {
+			t.Errorf("got=%q, want a warning [%s]", warnings, want)
+		}
+	}
+
+	if _, _, err := ImportGetopt("#!/bin/bash\necho hello\n"); !errors.Is(err, ErrMissingGetoptCall) {
+		t.Errorf("got=%v, want=%v", err, ErrMissingGetoptCall)
+	}
+}
+
+func TestMarshalCLIProgram(t *testing.T) {
+	t.Parallel()
+
+	cli := CLIProgram{
+		Help: "fetches articles",
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true, Required: true},
+			{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}},
+		},
+	}
+
+	content, err := MarshalCLIProgram(&cli)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	want := `help_message: fetches articles
+options:
+  - long_name: article
+    short_name: a
+    required: true
+    args_required: true
+  - long_name: page
+    short_name: p
+    conflicts_with:
+      - a
+`
+	if string(content) != want {
+		t.Errorf("got=[%s], want=[%s]", content, want)
+	}
+
+	var parsed CLIProgram
+	if err := yaml.Unmarshal(content, &parsed); err != nil || !reflect.DeepEqual(parsed, cli) {
+		t.Errorf("got=%+v (%v), want=%+v", parsed, err, cli)
+	}
+}
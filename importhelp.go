@@ -0,0 +1,249 @@
+package shellcligen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrMissingHelpOptions = errors.New("error missing options in help output")
+
+	helpOptionLineRegex = regexp.MustCompile(`^(\S.*?)(?:\s{2,}|\t)\s*(.*)$`)
+	helpLongOptionRegex = regexp.MustCompile(`^--([a-zA-Z0-9][a-zA-Z0-9_-]*)(?:(\[?)=(.*?)\]?)?$`)
+	helpShortOptionRgx  = regexp.MustCompile(`^-([a-zA-Z0-9])$`)
+	helpUsageRegex      = regexp.MustCompile(`(?i)^usage:`)
+	helpDefaultRegex    = regexp.MustCompile(`(?i)\s*[\[(]default(?: value)?(?: is)?[:=]?\s*([^\])]*)[\])]`)
+	helpRequiredRegex   = regexp.MustCompile(`(?i)\s*[\[(]required[\])]`)
+)
+
+// helpOption is an option read from a help output, along with what its description is made of.
+type helpOption struct {
+	option      CLIOption
+	placeholder string
+	description []string
+}
+
+// helpOptionTokens splits the names and placeholder of an option line, like `-a, --article=ID`,
+// keeping the commas of placeholders like `{json,yaml}`.
+func helpOptionTokens(spec string) []string {
+	tokens := make([]string, 0)
+
+	for _, field := range strings.Fields(spec) {
+		parts := strings.Split(strings.TrimSuffix(field, ","), ",")
+
+		allOptions := true
+		for _, part := range parts {
+			allOptions = allOptions && strings.HasPrefix(part, "-")
+		}
+
+		if allOptions {
+			tokens = append(tokens, parts...)
+		} else {
+			tokens = append(tokens, strings.TrimSuffix(field, ","))
+		}
+	}
+
+	return tokens
+}
+
+// parseHelpOptionLine parses a line describing an option, returning false when the line can not be
+// understood.
+func parseHelpOptionLine(line string) (helpOption, []string, bool) {
+	parsed := helpOption{}
+	warnings := make([]string, 0)
+
+	spec, description := strings.TrimSpace(line), ""
+	if match := helpOptionLineRegex.FindStringSubmatch(spec); match != nil {
+		spec, description = match[1], match[2]
+	}
+
+	optional := false
+
+	for _, token := range helpOptionTokens(spec) {
+		if match := helpLongOptionRegex.FindStringSubmatch(token); match != nil {
+			if len(parsed.option.LongName) > 0 {
+				return helpOption{}, nil, false
+			}
+
+			parsed.option.LongName = match[1]
+
+			if len(match[3]) > 0 {
+				parsed.placeholder, optional = match[3], optional || match[2] == "["
+			}
+
+			continue
+		}
+
+		if match := helpShortOptionRgx.FindStringSubmatch(token); match != nil {
+			if len(parsed.option.ShortName) > 0 {
+				return helpOption{}, nil, false
+			}
+
+			parsed.option.ShortName = match[1]
+
+			continue
+		}
+
+		if strings.HasPrefix(token, "-") || len(parsed.placeholder) > 0 && parsed.placeholder != token {
+			return helpOption{}, nil, false
+		}
+
+		parsed.placeholder = token
+		optional = optional || strings.HasPrefix(token, "[")
+	}
+
+	if len(parsed.option.ShortName) == 0 && len(parsed.option.LongName) == 0 {
+		return helpOption{}, nil, false
+	}
+
+	parsed.option.ArgsRequired = len(parsed.placeholder) > 0
+
+	if optional {
+		warnings = append(warnings, fmt.Sprintf("option %s takes an optional argument, imported as requiring one",
+			displayOptionName(&parsed.option)))
+	}
+
+	if len(description) > 0 {
+		parsed.description = append(parsed.description, description)
+	}
+
+	return parsed, warnings, true
+}
+
+// placeholderType guesses the type of an option from its placeholder, like FILE or {json,yaml}.
+func placeholderType(placeholder string) (string, []string) {
+	placeholder = strings.Trim(placeholder, "<>[]")
+
+	if strings.HasPrefix(placeholder, "{") && strings.HasSuffix(placeholder, "}") {
+		return enumType, strings.Split(strings.Trim(placeholder, "{}"), ",")
+	}
+
+	if strings.Contains(placeholder, "|") {
+		return enumType, strings.Split(placeholder, "|")
+	}
+
+	switch strings.ToUpper(placeholder) {
+	case "FILE", "FILENAME":
+		return fileType, nil
+	case "DIR", "DIRECTORY":
+		return dirType, nil
+	case "PATH":
+		return pathType, nil
+	case "N", "NUM", "NUMBER", "INT", "COUNT", "SECONDS":
+		return intType, nil
+	default:
+		return "", nil
+	}
+}
+
+// importedHelpOption turns what was read about an option into a CLIOption, taking its type from
+// the placeholder and whether it is required and its default value from the description.
+func importedHelpOption(parsed *helpOption) CLIOption {
+	cliOption := parsed.option
+	description := strings.Join(parsed.description, " ")
+
+	if cliOption.ArgsRequired {
+		cliOption.Type, cliOption.Choices = placeholderType(parsed.placeholder)
+
+		if match := helpDefaultRegex.FindStringSubmatch(description); match != nil {
+			if value := strings.Trim(strings.TrimSpace(match[1]), `"'`); isValueOfType(&cliOption, value) {
+				cliOption.Default = value
+				description = strings.Replace(description, match[0], "", 1)
+			}
+		}
+	}
+
+	if helpRequiredRegex.MatchString(description) {
+		cliOption.Required = true
+		description = helpRequiredRegex.ReplaceAllString(description, "")
+	}
+
+	cliOption.Description = strings.TrimSpace(description)
+	cliOption.Help = cliOption.LongName == "help" || (cliOption.ShortName == "h" && len(cliOption.LongName) == 0)
+
+	if cliOption.Help {
+		cliOption.ArgsRequired, cliOption.Type, cliOption.Choices = false, "", nil
+	}
+
+	return cliOption
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// isHelpSectionHeader tells whether the line titles a section of the help output, like `Options:`.
+func isHelpSectionHeader(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	return strings.HasSuffix(trimmed, ":") && !strings.HasPrefix(trimmed, "-") && !strings.Contains(trimmed, "  ")
+}
+
+// ImportHelp builds a program from the help output of a tool. Options are read from the lines
+// starting with a dash, indented lines following them continue their description and the text
+// before the first option becomes the program help. The usage ends at a blank line or at the first
+// option. Lines which can not be understood, usage lines included, are reported in the returned
+// warnings.
+func ImportHelp(help string) (CLIProgram, []string, error) {
+	cli := CLIProgram{}
+	warnings := make([]string, 0)
+	parsedOptions := make([]helpOption, 0)
+	programHelp := make([]string, 0)
+
+	var current *helpOption
+
+	currentIndentation, optionsSeen, inUsage := 0, false, false
+
+	for i, line := range strings.Split(strings.ReplaceAll(help, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case len(trimmed) == 0:
+			current, inUsage = nil, false
+		case helpUsageRegex.MatchString(trimmed):
+			current, inUsage = nil, true
+		case inUsage && indentation(line) > 0 && !strings.HasPrefix(trimmed, "-"):
+			warnings = append(warnings, fmt.Sprintf("line %d: skipped usage: %s", i+1, trimmed))
+		case current != nil && !strings.HasPrefix(trimmed, "-") && indentation(line) > currentIndentation:
+			current.description = append(current.description, trimmed)
+		case strings.HasPrefix(trimmed, "-"):
+			parsed, optionWarnings, ok := parseHelpOptionLine(line)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("line %d: could not parse option: %s", i+1, trimmed))
+				current = nil
+
+				continue
+			}
+
+			warnings = append(warnings, optionWarnings...)
+			parsedOptions = append(parsedOptions, parsed)
+			current, currentIndentation, optionsSeen, inUsage = &parsedOptions[len(parsedOptions)-1], indentation(line), true, false
+		case isHelpSectionHeader(line):
+			current, optionsSeen, inUsage = nil, true, false
+		case !optionsSeen:
+			programHelp = append(programHelp, trimmed)
+		default:
+			warnings = append(warnings, fmt.Sprintf("line %d: skipped: %s", i+1, trimmed))
+			current = nil
+		}
+	}
+
+	if len(parsedOptions) == 0 {
+		return CLIProgram{}, warnings, fmt.Errorf("error importing help output: %w", ErrMissingHelpOptions)
+	}
+
+	cli.Help = strings.Join(programHelp, "\n")
+
+	for i := range parsedOptions {
+		cliOption := importedHelpOption(&parsedOptions[i])
+		cli.Options = append(cli.Options, cliOption)
+
+		if warning := importedOptionWarning(&cliOption); len(warning) > 0 {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return cli, warnings, nil
+}
@@ -0,0 +1,186 @@
+package shellcligen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const toolHelpOutput = `Usage: fetch [OPTIONS] URL...
+       fetch --version
+
+Fetch articles from the archive.
+
+Options:
+  -a, --article ID        article to fetch (required)
+  -f, --format={json,yaml}
+                          output format [default: json]
+  -o FILE, --output=FILE  where to write the result
+      --color[=WHEN]      colorize the output
+  -v, --verbose           be chatty,
+                          even more with -vv
+  -h, --help              show this help and exit
+  -xyz                    legacy flag
+
+Examples:
+  fetch -a 12 https://example.com
+`
+
+func Test_helpOptionTokens(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		spec string
+		want []string
+	}
+
+	tests := []test{
+		{spec: "-a, --article ID", want: []string{"-a", "--article", "ID"}},
+		{spec: "-a,--article", want: []string{"-a", "--article"}},
+		{spec: "-f, --format {json,yaml}", want: []string{"-f", "--format", "{json,yaml}"}},
+	}
+
+	for _, tt := range tests {
+		if got := helpOptionTokens(tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got=%q, want=%q", got, tt.want)
+		}
+	}
+}
+
+func Test_parseHelpOptionLine(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		line            string
+		wantOption      CLIOption
+		wantPlaceholder string
+		wantOk          bool
+	}
+
+	tests := []test{
+		{
+			line:            "  -a, --article=ID    article to fetch",
+			wantOption:      CLIOption{ShortName: "a", LongName: "article", ArgsRequired: true},
+			wantPlaceholder: "ID",
+			wantOk:          true,
+		},
+		{
+			line:            "  -o <file>",
+			wantOption:      CLIOption{ShortName: "o", ArgsRequired: true},
+			wantPlaceholder: "<file>",
+			wantOk:          true,
+		},
+		{
+			line:       "  --verbose\tbe chatty",
+			wantOption: CLIOption{LongName: "verbose"},
+			wantOk:     true,
+		},
+		{line: "  -xyz   legacy flag", wantOk: false},
+		{line: "  -a, -b   two short names", wantOk: false},
+		{line: "  -a ID NAME   two placeholders", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, _, ok := parseHelpOptionLine(tt.line)
+		if ok != tt.wantOk {
+			t.Errorf("got=%t, want=%t for [%s]", ok, tt.wantOk, tt.line)
+
+			continue
+		}
+
+		if ok && (!reflect.DeepEqual(got.option, tt.wantOption) || got.placeholder != tt.wantPlaceholder) {
+			t.Errorf("got=%+v [%s], want=%+v [%s]", got.option, got.placeholder, tt.wantOption, tt.wantPlaceholder)
+		}
+	}
+}
+
+func Test_placeholderType(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		placeholder string
+		wantType    string
+		wantChoices []string
+	}
+
+	tests := []test{
+		{placeholder: "<FILE>", wantType: "file"},
+		{placeholder: "dir", wantType: "dir"},
+		{placeholder: "N", wantType: "int"},
+		{placeholder: "{json,yaml}", wantType: "enum", wantChoices: []string{"json", "yaml"}},
+		{placeholder: "<always|never>", wantType: "enum", wantChoices: []string{"always", "never"}},
+		{placeholder: "ID", wantType: ""},
+	}
+
+	for _, tt := range tests {
+		gotType, gotChoices := placeholderType(tt.placeholder)
+		if gotType != tt.wantType || !reflect.DeepEqual(gotChoices, tt.wantChoices) {
+			t.Errorf("got=%s %v, want=%s %v", gotType, gotChoices, tt.wantType, tt.wantChoices)
+		}
+	}
+}
+
+func TestImportHelp(t *testing.T) {
+	t.Parallel()
+
+	cli, warnings, err := ImportHelp(toolHelpOutput)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	want := CLIProgram{
+		Help: "Fetch articles from the archive.",
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true, Required: true, Description: "article to fetch"},
+			{
+				ShortName: "f", LongName: "format", ArgsRequired: true, Description: "output format",
+				Type: "enum", Choices: []string{"json", "yaml"}, Default: "json",
+			},
+			{ShortName: "o", LongName: "output", ArgsRequired: true, Description: "where to write the result", Type: "file"},
+			{LongName: "color", ArgsRequired: true, Description: "colorize the output"},
+			{ShortName: "v", LongName: "verbose", Description: "be chatty, even more with -vv"},
+			{ShortName: "h", LongName: "help", Help: true, Description: "show this help and exit"},
+		},
+	}
+
+	if !reflect.DeepEqual(cli, want) {
+		t.Errorf("got=%+v, want=%+v", cli, want)
+	}
+
+	wantWarnings := []string{
+		"line 2: skipped usage: fetch --version",
+		"option --color takes an optional argument, imported as requiring one",
+		"line 15: could not parse option: -xyz                    legacy flag",
+		"line 18: skipped: fetch -a 12 https://example.com",
+		"option --color needs both a short and a long name",
+	}
+
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("got=%q, want=%q", warnings, wantWarnings)
+	}
+
+	cli, warnings, err = ImportHelp("usage: tool [-a] [-b FILE]\n  -a  all\n  -b FILE  file\n")
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	want = CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", Description: "all"},
+			{ShortName: "b", ArgsRequired: true, Description: "file", Type: "file"},
+		},
+	}
+
+	if !reflect.DeepEqual(cli, want) {
+		t.Errorf("got=%+v, want=%+v", cli, want)
+	}
+
+	wantWarnings = []string{"option -a needs both a short and a long name", "option -b needs both a short and a long name"}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("got=%q, want=%q", warnings, wantWarnings)
+	}
+
+	if _, _, err := ImportHelp("fetch articles\n"); !errors.Is(err, ErrMissingHelpOptions) {
+		t.Errorf("got=%v, want=%v", err, ErrMissingHelpOptions)
+	}
+}
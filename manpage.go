@@ -0,0 +1,255 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// manPageFileName returns the man page file name, it is named after the script so `man script.sh`
+// finds it once installed in a man1 directory.
+func manPageFileName(cli *CLIProgram) string {
+	return scriptName(cli) + ".1"
+}
+
+// roffEscape escapes text so roff prints it as is.
+func roffEscape(text string) string {
+	replacer := strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+	lines := strings.Split(replacer.Replace(text), "\n")
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// roffParagraphs escapes text and turns its blank lines into paragraph breaks.
+func roffParagraphs(text string) string {
+	paragraphs := make([]string, 0)
+
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); len(paragraph) > 0 {
+			paragraphs = append(paragraphs, roffEscape(paragraph))
+		}
+	}
+
+	return strings.Join(paragraphs, "\n.PP\n")
+}
+
+func manArgumentName(cliOption *CLIOption) string {
+	return strings.TrimSuffix(strings.TrimPrefix(argPlaceholder(cliOption), "<"), ">")
+}
+
+// manOptionNames returns the names of the option in bold, followed by its argument in italics.
+func manOptionNames(cliOption *CLIOption, separator string) string {
+	names := make([]string, 0, 2)
+
+	for _, name := range optionNames(cliOption) {
+		names = append(names, fmt.Sprintf(`\fB%s\fR`, roffEscape(name)))
+	}
+
+	column := strings.Join(names, separator)
+	if cliOption.ArgsRequired {
+		column += fmt.Sprintf(` \fI%s\fR`, roffEscape(manArgumentName(cliOption)))
+	}
+
+	return column
+}
+
+func manScopeCommand(scope *commandScope) string {
+	return strings.TrimSpace(scriptName(&scope.cli) + " " + commandPath(scope))
+}
+
+// manSynopsisLine returns the synopsis of a scope, optional options are enclosed in brackets and
+// required ones are not.
+func manSynopsisLine(scope *commandScope) string {
+	parts := make([]string, 0)
+
+	for i := range scope.cli.Options {
+		names := manOptionNames(&scope.cli.Options[i], "|")
+		if !scope.cli.Options[i].Required {
+			names = "[" + names + "]"
+		}
+
+		parts = append(parts, names)
+	}
+
+	if len(scope.cli.Commands) > 0 {
+		parts = append(parts, `\fIcommand\fR [\fIargs\fR]`)
+	}
+
+	for i := range scope.cli.Positionals {
+		parts = append(parts, roffEscape(positionalUsage(&scope.cli.Positionals[i])))
+	}
+
+	return fmt.Sprintf(".B %s\n%s\n", roffEscape(manScopeCommand(scope)), strings.Join(parts, "\n"))
+}
+
+func generateManSynopsis(scopes []commandScope) string {
+	synopses := make([]string, 0, len(scopes))
+
+	for i := range scopes {
+		synopses = append(synopses, manSynopsisLine(&scopes[i]))
+	}
+
+	return ".SH SYNOPSIS\n" + strings.Join(synopses, ".br\n")
+}
+
+// generateManOptions lists the options declared by a scope, along with the notes the usage shows
+// for them: whether they are required, their default, environment variable and conflicts.
+func generateManOptions(scope *commandScope) string {
+	var optionsSb strings.Builder
+
+	program := scopeProgram(scope)
+
+	for i := len(scope.inherited); i < len(program.Options); i++ {
+		optionsSb.WriteString(fmt.Sprintf(".TP\n%s\n", manOptionNames(&program.Options[i], ", ")))
+
+		if description := usageDescriptionColumn(&program, i); len(description) > 0 {
+			optionsSb.WriteString(roffEscape(description) + "\n")
+		}
+	}
+
+	return optionsSb.String()
+}
+
+func generateManArguments(cli *CLIProgram) string {
+	var argumentsSb strings.Builder
+
+	for i := range cli.Positionals {
+		argumentsSb.WriteString(fmt.Sprintf(".TP\n\\fI%s\\fR\n", roffEscape(positionalUsage(&cli.Positionals[i]))))
+
+		if description := positionalDescription(&cli.Positionals[i]); len(description) > 0 {
+			argumentsSb.WriteString(roffEscape(description) + "\n")
+		}
+	}
+
+	return argumentsSb.String()
+}
+
+// generateManCommands documents every command in its own subsection, in the order the usage of the
+// script lists them.
+func generateManCommands(scopes []commandScope) string {
+	var commandsSb strings.Builder
+
+	for i := 1; i < len(scopes); i++ {
+		scope := &scopes[i]
+
+		commandsSb.WriteString(fmt.Sprintf(".SS %s\n", roffEscape(commandPath(scope))))
+
+		if help := roffParagraphs(scope.cli.Help); len(help) > 0 {
+			commandsSb.WriteString(help + "\n")
+		}
+
+		commandsSb.WriteString(generateManArguments(&scope.cli))
+		commandsSb.WriteString(generateManOptions(scope))
+	}
+
+	return commandsSb.String()
+}
+
+func generateManEnvironment(scopes []commandScope) string {
+	var environmentSb strings.Builder
+
+	for i := range scopes {
+		for j := range scopes[i].cli.Options {
+			cliOption := &scopes[i].cli.Options[j]
+
+			env := strings.TrimSpace(cliOption.Env)
+			if len(env) == 0 {
+				continue
+			}
+
+			description := "Value of " + displayOptionName(cliOption)
+			if len(scopes[i].path) > 0 {
+				description += " for the " + commandPath(&scopes[i]) + " command"
+			}
+
+			environmentSb.WriteString(fmt.Sprintf(".TP\n.B %s\n%s, the command line takes precedence over it.\n",
+				roffEscape(env), roffEscape(description)))
+		}
+	}
+
+	return environmentSb.String()
+}
+
+func generateManExamples(cli *CLIProgram) string {
+	var examplesSb strings.Builder
+
+	for _, example := range scriptExamples(cli) {
+		examplesSb.WriteString(fmt.Sprintf(".PP\n%s:\n.PP\n.RS\n.nf\n%s\n.fi\n.RE\n",
+			roffEscape(example.description), roffEscape(example.command)))
+	}
+
+	return examplesSb.String()
+}
+
+func generateManExitStatus() string {
+	var statusSb strings.Builder
+
+	statusSb.WriteString(".SH EXIT STATUS\n")
+
+	for _, status := range exitStatuses {
+		statusSb.WriteString(fmt.Sprintf(".TP\n.B %d\n%s\n", status.code, roffEscape(status.description)))
+	}
+
+	statusSb.WriteString(".PP\n" + roffEscape(otherExitStatusDescription) + "\n")
+
+	return statusSb.String()
+}
+
+// generateManPage generates a section 1 man page for the script from the same program description
+// the script is generated from.
+func generateManPage(cli *CLIProgram) string {
+	var manSb strings.Builder
+
+	scopes := commandScopes(cli)
+
+	manSb.WriteString(fmt.Sprintf(".TH %s 1\n", roffEscape(strings.ToUpper(scriptName(cli)))))
+	manSb.WriteString(".SH NAME\n")
+	manSb.WriteString(roffEscape(scriptName(cli)))
+
+	if summary := firstHelpLine(cli.Help); len(summary) > 0 {
+		manSb.WriteString(` \- ` + roffEscape(summary))
+	}
+
+	manSb.WriteString("\n" + generateManSynopsis(scopes))
+
+	if description := roffParagraphs(cli.Help); len(description) > 0 {
+		manSb.WriteString(".SH DESCRIPTION\n" + description + "\n")
+	}
+
+	if arguments := generateManArguments(cli); len(arguments) > 0 {
+		manSb.WriteString(".SH ARGUMENTS\n" + arguments)
+	}
+
+	if options := generateManOptions(&scopes[0]); len(options) > 0 {
+		manSb.WriteString(".SH OPTIONS\n" + options)
+	}
+
+	if commands := generateManCommands(scopes); len(commands) > 0 {
+		manSb.WriteString(".SH COMMANDS\n")
+		manSb.WriteString("The options of the script and of a command are also accepted by its subcommands.\n")
+		manSb.WriteString(commands)
+	}
+
+	if environment := generateManEnvironment(scopes); len(environment) > 0 {
+		manSb.WriteString(".SH ENVIRONMENT\n" + environment)
+	}
+
+	manSb.WriteString(fmt.Sprintf(`.SH FILES
+.TP
+.I %s
+Option values read from the directory of the script, one key=value per line. Values given on the
+command line or through environment variables take precedence over it.
+`, roffEscape(configFileName(cli))))
+	manSb.WriteString(generateManExitStatus())
+
+	if examples := generateManExamples(cli); len(examples) > 0 {
+		manSb.WriteString(".SH EXAMPLES\n" + examples)
+	}
+
+	return manSb.String()
+}
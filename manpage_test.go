@@ -0,0 +1,93 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_roffEscape(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		text string
+		want string
+	}
+
+	tests := []test{
+		{text: "--verbose", want: `\-\-verbose`},
+		{text: `C:\tmp`, want: `C:\etmp`},
+		{text: "first\n.second\n'third", want: "first\n\\&.second\n\\&'third"},
+	}
+
+	for _, tt := range tests {
+		if got := roffEscape(tt.text); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_manSynopsisLine(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		scope commandScope
+		want  string
+	}
+
+	tests := []test{
+		{
+			scope: commandScope{cli: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "article", ArgsRequired: true, Required: true},
+					{ShortName: "v", LongName: "verbose"},
+				},
+				Positionals: []Positional{{Name: "files", Variadic: true}},
+			}},
+			want: ".B script.sh\n" +
+				`\fB\-a\fR|\fB\-\-article\fR \fIARG\fR` + "\n" +
+				`[\fB\-v\fR|\fB\-\-verbose\fR]` + "\n" +
+				"[<files>...]\n",
+		},
+		{
+			scope: commandScope{path: []string{"db"}, cli: CLIProgram{Commands: []Command{{Name: "migrate"}}}},
+			want:  ".B script.sh db\n" + `\fIcommand\fR [\fIargs\fR]` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := manSynopsisLine(&tt.scope); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateManPage(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	cliProgram.Commands[0].Options[0].Env = "DEPLOY_ENV"
+	cliProgram.Commands[0].Options = append(cliProgram.Commands[0].Options,
+		CLIOption{ShortName: "n", LongName: "dryrun", ConflictsWith: []string{"v"}})
+
+	got := generateManPage(&cliProgram)
+
+	for _, want := range []string{
+		".TH SCRIPT.SH 1\n",
+		".SH NAME\nscript.sh \\- deployment tool\n",
+		".SH SYNOPSIS\n.B script.sh\n",
+		".SH DESCRIPTION\ndeployment tool\n",
+		".SH OPTIONS\n.TP\n\\fB\\-v\\fR, \\fB\\-\\-verbose\\fR\n",
+		".SS db migrate\n",
+		"\\fB\\-e\\fR, \\fB\\-\\-env\\fR \\fIARG\\fR\n(required) (env: DEPLOY_ENV)\n",
+		"(conflicts with \\-v/\\-\\-verbose)\n",
+		".SH ENVIRONMENT\n.TP\n.B DEPLOY_ENV\n",
+		".SH FILES\n.TP\n.I script.conf\n",
+		".SH EXIT STATUS\n",
+		".SH EXAMPLES\n",
+		"script.sh deploy \\-e arg target\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("man page does not contain [%s]:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func markdownFileName(cli *CLIProgram) string {
+	return scriptBaseName(cli) + ".md"
+}
+
+// markdownCell escapes text so it fits in a single table cell.
+func markdownCell(text string) string {
+	replacer := strings.NewReplacer(`|`, `\|`, "\n", " ")
+
+	return replacer.Replace(strings.TrimSpace(text))
+}
+
+func markdownCode(text string) string {
+	if len(text) == 0 {
+		return ""
+	}
+
+	return "`" + text + "`"
+}
+
+func writeMarkdownTable(markdownSb *strings.Builder, header []string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+
+	separators := make([]string, 0, len(header))
+	for range header {
+		separators = append(separators, "---")
+	}
+
+	markdownSb.WriteString("\n| " + strings.Join(header, " | ") + " |\n")
+	markdownSb.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+
+	for _, row := range rows {
+		markdownSb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
+
+func markdownArgumentsTable(markdownSb *strings.Builder, cli *CLIProgram) {
+	rows := make([][]string, 0, len(cli.Positionals))
+
+	for i := range cli.Positionals {
+		rows = append(rows, []string{
+			markdownCode(markdownCell(positionalUsage(&cli.Positionals[i]))),
+			markdownCell(positionalDescription(&cli.Positionals[i])),
+		})
+	}
+
+	writeMarkdownTable(markdownSb, []string{"Argument", "Description"}, rows)
+}
+
+// markdownOptionsTable writes the table of the options declared by a scope, one column per
+// property of the option so readers can scan them.
+func markdownOptionsTable(markdownSb *strings.Builder, scope *commandScope) {
+	program := scopeProgram(scope)
+	rows := make([][]string, 0, len(scope.cli.Options))
+
+	for i := len(scope.inherited); i < len(program.Options); i++ {
+		cliOption := &program.Options[i]
+
+		shortOptionName, longOptionName := "", ""
+		if name := strings.TrimSpace(cliOption.ShortName); len(name) > 0 {
+			shortOptionName = "-" + name
+		}
+
+		if name := strings.TrimSpace(cliOption.LongName); len(name) > 0 {
+			longOptionName = "--" + name
+		}
+
+		argument, required := "", "no"
+		if cliOption.ArgsRequired {
+			argument = argPlaceholder(cliOption)
+		}
+
+		if cliOption.Required {
+			required = "yes"
+		}
+
+		conflicts := conflictingOptionNames(&program, i)
+		for j := range conflicts {
+			conflicts[j] = markdownCode(conflicts[j])
+		}
+
+		rows = append(rows, []string{
+			markdownCode(shortOptionName),
+			markdownCode(longOptionName),
+			markdownCode(markdownCell(argument)),
+			required,
+			strings.Join(conflicts, ", "),
+			markdownCode(markdownCell(cliOption.Default)),
+			markdownCode(markdownCell(cliOption.Env)),
+			markdownCell(cliOption.Description),
+		})
+	}
+
+	writeMarkdownTable(markdownSb, []string{
+		"Short", "Long", "Argument", "Required", "Conflicts with", "Default", "Environment variable", "Description",
+	}, rows)
+}
+
+func writeMarkdownScope(markdownSb *strings.Builder, scope *commandScope) {
+	markdownSb.WriteString(fmt.Sprintf("\n```\n%s\n```\n", scopeSynopsis(scope, scriptName(&scope.cli))))
+
+	if len(scope.cli.Commands) > 0 {
+		rows := make([][]string, 0, len(scope.cli.Commands))
+		for _, command := range scope.cli.Commands {
+			rows = append(rows, []string{markdownCode(strings.TrimSpace(command.Name)), markdownCell(firstHelpLine(command.Help))})
+		}
+
+		writeMarkdownTable(markdownSb, []string{"Command", "Description"}, rows)
+	}
+
+	markdownArgumentsTable(markdownSb, &scope.cli)
+	markdownOptionsTable(markdownSb, scope)
+}
+
+// generateMarkdown generates the reference documentation of the script in Markdown, meant to be
+// published along with it.
+func generateMarkdown(cli *CLIProgram) string {
+	var markdownSb strings.Builder
+
+	scopes := commandScopes(cli)
+
+	markdownSb.WriteString(fmt.Sprintf("# %s\n", scriptName(cli)))
+
+	if help := strings.TrimSpace(cli.Help); len(help) > 0 {
+		markdownSb.WriteString("\n" + help + "\n")
+	}
+
+	markdownSb.WriteString("\n## Usage\n")
+	writeMarkdownScope(&markdownSb, &scopes[0])
+
+	if len(scopes) > 1 {
+		markdownSb.WriteString("\n## Commands\n\n")
+		markdownSb.WriteString("The options of the script and of a command are also accepted by its subcommands.\n")
+
+		for i := 1; i < len(scopes); i++ {
+			markdownSb.WriteString(fmt.Sprintf("\n### %s\n", commandPath(&scopes[i])))
+
+			if help := strings.TrimSpace(scopes[i].cli.Help); len(help) > 0 {
+				markdownSb.WriteString("\n" + help + "\n")
+			}
+
+			writeMarkdownScope(&markdownSb, &scopes[i])
+		}
+	}
+
+	markdownSb.WriteString(fmt.Sprintf(`
+## Configuration
+
+Option values are taken from the command line first, then from their environment variables, then
+from %s, read from the directory of the script, and finally from their default values.
+`, markdownCode(configFileName(cli))))
+
+	if examples := scriptExamples(cli); len(examples) > 0 {
+		markdownSb.WriteString("\n## Examples\n")
+
+		for _, example := range examples {
+			markdownSb.WriteString(fmt.Sprintf("\n%s:\n\n```sh\n%s\n```\n", example.description, example.command))
+		}
+	}
+
+	markdownSb.WriteString("\n## Exit codes\n")
+
+	rows := make([][]string, 0, len(exitStatuses))
+	for _, status := range exitStatuses {
+		rows = append(rows, []string{markdownCode(fmt.Sprint(status.code)), status.description})
+	}
+
+	writeMarkdownTable(&markdownSb, []string{"Code", "Meaning"}, rows)
+	markdownSb.WriteString("\n" + otherExitStatusDescription + "\n")
+
+	return markdownSb.String()
+}
@@ -0,0 +1,74 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_markdownCell(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		text string
+		want string
+	}
+
+	tests := []test{
+		{text: "<json|yaml>", want: `<json\|yaml>`},
+		{text: " first line\nsecond line ", want: "first line second line"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownCell(tt.text); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_markdownOptionsTable(t *testing.T) {
+	t.Parallel()
+
+	scope := commandScope{cli: CLIProgram{Options: []CLIOption{
+		{ShortName: "f", LongName: "format", ArgsRequired: true, Required: true, Type: "enum", Choices: []string{"json", "yaml"}, Env: "FORMAT"},
+		{ShortName: "p", LongName: "page", ArgsRequired: true, Type: "int", Default: "1", ConflictsWith: []string{"a"}, Description: "page to fetch"},
+		{ShortName: "a", LongName: "all"},
+	}}}
+
+	want := `
+| Short | Long | Argument | Required | Conflicts with | Default | Environment variable | Description |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+| ` + "`-f` | `--format` | `<json\\|yaml>` | yes |  |  | `FORMAT` |  |" + `
+| ` + "`-p` | `--page` | `<INT>` | no | `-a/--all` | `1` |  | page to fetch |" + `
+| ` + "`-a` | `--all` |  | no | `-p/--page` |  |  |  |" + `
+`
+
+	var got strings.Builder
+	if markdownOptionsTable(&got, &scope); got.String() != want {
+		t.Errorf("got=[%s], want=[%s]", got.String(), want)
+	}
+}
+
+func Test_generateMarkdown(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateMarkdown(&cliProgram)
+
+	for _, want := range []string{
+		"# script.sh\n\ndeployment tool\n",
+		"## Usage\n\n```\nscript.sh [OPTIONS] <command> [ARGS]\n```\n",
+		"| `deploy` | deploy the application |\n",
+		"### db migrate\n",
+		"```\nscript.sh deploy [OPTIONS] <target>\n```\n",
+		"| `<target>` | (required) |\n",
+		"| `-s` | `--steps` | `<INT>` | no |  | `1` |  |  |\n",
+		"## Examples\n",
+		"```sh\nscript.sh deploy -e arg target\n```\n",
+		"## Exit codes\n",
+		"| `2` | Invalid usage:",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown does not contain [%s]:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,457 @@
+package shellcligen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownKey = errors.New("error unknown key")
+
+	yamlErrorRegex        = regexp.MustCompile(`^(?:yaml: )?line (\d+): (?:column (\d+): )?(.*)$`)
+	tomlErrorRegex        = regexp.MustCompile(`^toml: line (\d+)(?: \([^)]*\))?: (.*)$`)
+	jsonUnknownFieldRegex = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+	positionType          = reflect.TypeOf(Position{})
+)
+
+// yamlKeyName returns the key a struct field is read from.
+func yamlKeyName(field *reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+// editDistance is the Levenshtein distance between two keys.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// closestKey returns the known key the unknown one is most likely a typo of, if any.
+func closestKey(key string, known []string) string {
+	closest, closestDistance := "", 3
+
+	for _, name := range known {
+		if distance := editDistance(key, name); distance < closestDistance {
+			closest, closestDistance = name, distance
+		}
+	}
+
+	return closest
+}
+
+func unknownKeyError(key string, known []string, pos Position) *ValidationError {
+	message := "unknown key"
+	if closest := closestKey(key, known); len(closest) > 0 {
+		message += ", did you mean " + strconv.Quote(closest) + "?"
+	}
+
+	return &ValidationError{Position: pos, Rule: RuleUnknownKey, Field: key, Message: message, Err: ErrUnknownKey}
+}
+
+// nodePosition returns where a YAML node is declared.
+func nodePosition(node *yaml.Node) Position {
+	return Position{Line: node.Line, Column: node.Column}
+}
+
+// annotateNode walks a decoded YAML node along with the value it was decoded into, setting the
+// Position of every struct holding one and reporting the keys not matching any field.
+func annotateNode(node *yaml.Node, value reflect.Value, errs *[]*ValidationError) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		annotateNode(node.Content[0], value, errs)
+
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for i, item := range node.Content {
+			if i < value.Len() {
+				annotateNode(item, value.Index(i), errs)
+			}
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		if field := value.FieldByName("Position"); field.IsValid() && field.Type() == positionType {
+			field.Set(reflect.ValueOf(nodePosition(node)))
+		}
+
+		fields := make(map[string]int)
+		known := make([]string, 0, value.NumField())
+		keyPositions := make(map[string]Position, len(node.Content)/2)
+
+		for i := 0; i < value.NumField(); i++ {
+			structField := value.Type().Field(i)
+			if name := yamlKeyName(&structField); len(name) > 0 && name != "-" {
+				fields[name] = i
+				known = append(known, name)
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, item := node.Content[i], node.Content[i+1]
+			keyPositions[key.Value] = nodePosition(key)
+
+			index, ok := fields[key.Value]
+			if !ok {
+				err := unknownKeyError(key.Value, known, nodePosition(key))
+				if cliOption, isOption := value.Interface().(CLIOption); isOption {
+					err.Option = displayOptionName(&cliOption)
+				}
+
+				*errs = append(*errs, err)
+
+				continue
+			}
+
+			annotateNode(item, value.Field(index), errs)
+		}
+
+		setKeyPositions(value, keyPositions)
+	}
+}
+
+// setKeyPositions remembers where the keys of options, and the name of the program, are declared.
+func setKeyPositions(value reflect.Value, keyPositions map[string]Position) {
+	if !value.CanAddr() {
+		return
+	}
+
+	switch decoded := value.Addr().Interface().(type) {
+	case *CLIOption:
+		decoded.keyPositions = keyPositions
+	case *CLIProgram:
+		decoded.namePosition = keyPositions["name"]
+	}
+}
+
+// lineColumn returns the column of the first node declared on a line, 0 if there is none.
+func lineColumn(node *yaml.Node, line int) int {
+	if node.Line == line && node.Kind != yaml.DocumentNode {
+		return node.Column
+	}
+
+	for _, child := range node.Content {
+		if column := lineColumn(child, line); column > 0 {
+			return column
+		}
+	}
+
+	return 0
+}
+
+// yamlError turns a message of the YAML decoder, like `line 3: cannot unmarshal ...`, into a
+// ValidationError located at the line it reports.
+func yamlError(message string, root *yaml.Node) *ValidationError {
+	err := &ValidationError{Rule: RuleSyntax, Message: message, Err: ErrParsingInputFile}
+
+	if match := yamlErrorRegex.FindStringSubmatch(message); match != nil {
+		err.Position.Line, _ = strconv.Atoi(match[1])
+		err.Position.Column, _ = strconv.Atoi(match[2])
+		err.Message = match[3]
+
+		if err.Position.Column == 0 && root != nil {
+			err.Position.Column = lineColumn(root, err.Position.Line)
+		}
+	}
+
+	return err
+}
+
+// decodeYAML decodes a YAML program description, remembering where its options,
+// positionals and commands are declared. Syntax errors, values of the wrong type and unknown keys
+// are all reported.
+func decodeYAML(content []byte, cli *CLIProgram) []*ValidationError {
+	root := yaml.Node{}
+
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return []*ValidationError{yamlError(err.Error(), nil)}
+	}
+
+	errs := make([]*ValidationError, 0)
+
+	if err := root.Decode(cli); err != nil {
+		var typeError *yaml.TypeError
+		if !errors.As(err, &typeError) {
+			return []*ValidationError{yamlError(err.Error(), &root)}
+		}
+
+		for _, message := range typeError.Errors {
+			errs = append(errs, yamlError(message, &root))
+		}
+	}
+
+	annotateNode(&root, reflect.ValueOf(cli).Elem(), &errs)
+
+	return errs
+}
+
+// jsonError turns an error of the JSON decoder into a ValidationError located at the offset it
+// reports.
+func jsonError(content []byte, err error) *ValidationError {
+	validationError := &ValidationError{
+		Rule:    RuleSyntax,
+		Message: strings.TrimPrefix(err.Error(), "json: "),
+		Err:     ErrParsingInputFile,
+	}
+
+	var (
+		syntaxError *json.SyntaxError
+		typeError   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxError):
+		validationError.Position = offsetPosition(content, int(syntaxError.Offset))
+	case errors.As(err, &typeError):
+		validationError.Position = offsetPosition(content, int(typeError.Offset))
+	}
+
+	return validationError
+}
+
+// decodeJSON decodes a JSON program description with encoding/json. The positions of its options,
+// positionals and commands, and its unknown keys, come from reading it again as YAML. The few JSON
+// descriptions YAML can't read, like ones escaping '/', are given no position and only their first
+// unknown key is reported.
+func decodeJSON(content []byte, cli *CLIProgram) []*ValidationError {
+	if err := json.Unmarshal(content, cli); err != nil {
+		return []*ValidationError{jsonError(content, err)}
+	}
+
+	errs := make([]*ValidationError, 0)
+
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(content, &root); err == nil {
+		annotateNode(&root, reflect.ValueOf(cli).Elem(), &errs)
+
+		return errs
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&CLIProgram{}); err != nil {
+		if match := jsonUnknownFieldRegex.FindStringSubmatch(err.Error()); match != nil {
+			errs = append(errs, unknownKeyError(match[1], nil, Position{}))
+		}
+	}
+
+	return errs
+}
+
+// offsetPosition returns the position of a byte offset of content.
+func offsetPosition(content []byte, offset int) Position {
+	if offset > len(content) {
+		offset = len(content)
+	}
+
+	before := string(content[:offset])
+
+	return Position{
+		Line:   strings.Count(before, "\n") + 1,
+		Column: offset - strings.LastIndex(before, "\n"),
+	}
+}
+
+// tomlKeyParts splits a dotted TOML key, like `commands.options`, unquoting its parts.
+func tomlKeyParts(key string) []string {
+	parts := strings.Split(key, ".")
+	for i := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(parts[i]), `"'`)
+	}
+
+	return parts
+}
+
+// tomlChild returns the node of a key of a mapping node, created with kind when missing. The last
+// table of an array of tables stands for the array.
+func tomlChild(node *yaml.Node, key string, kind yaml.Kind, pos Position) *yaml.Node {
+	child := mappingValue(node, key)
+	if child == nil {
+		child = &yaml.Node{Kind: kind, Line: pos.Line, Column: pos.Column}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key, Line: pos.Line, Column: pos.Column}, child)
+	}
+
+	if child.Kind == yaml.SequenceNode && kind == yaml.MappingNode && len(child.Content) > 0 {
+		return child.Content[len(child.Content)-1]
+	}
+
+	return child
+}
+
+// tomlUnclosed returns the brackets a TOML value opens and leaves unclosed, skipping strings.
+func tomlUnclosed(value string) int {
+	depth := 0
+	quote := rune(0)
+
+	for _, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == '#':
+			return depth
+		}
+	}
+
+	return depth
+}
+
+// tomlNode returns the tables and keys of a TOML document as a YAML node tree, lines and columns
+// included, so annotateNode can locate them. Values are left out, the multi-line ones are skipped.
+func tomlNode(content []byte) *yaml.Node {
+	root := &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}
+	table := root
+	unclosed, multiLine := 0, ""
+
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		pos := Position{Line: i + 1, Column: len(line) - len(strings.TrimLeft(line, " \t")) + 1}
+
+		switch {
+		case len(multiLine) > 0:
+			if strings.Contains(trimmed, multiLine) {
+				multiLine = ""
+			}
+		case unclosed > 0:
+			unclosed += tomlUnclosed(trimmed)
+		case len(trimmed) == 0 || strings.HasPrefix(trimmed, "#"):
+		case strings.HasPrefix(trimmed, "["):
+			isArray := strings.HasPrefix(trimmed, "[[")
+			parts := tomlKeyParts(strings.Trim(strings.SplitN(trimmed, "]", 2)[0], "[ "))
+
+			table = root
+			for _, part := range parts[:len(parts)-1] {
+				table = tomlChild(table, part, yaml.MappingNode, pos)
+			}
+
+			last := parts[len(parts)-1]
+
+			if !isArray {
+				table = tomlChild(table, last, yaml.MappingNode, pos)
+
+				continue
+			}
+
+			tables := tomlChild(table, last, yaml.SequenceNode, pos)
+			table = &yaml.Node{Kind: yaml.MappingNode, Line: pos.Line, Column: pos.Column}
+			tables.Content = append(tables.Content, table)
+		case strings.Contains(trimmed, "="):
+			assignment := strings.SplitN(trimmed, "=", 2)
+			parts := tomlKeyParts(assignment[0])
+			value := strings.TrimSpace(assignment[1])
+
+			node := table
+			for _, part := range parts[:len(parts)-1] {
+				node = tomlChild(node, part, yaml.MappingNode, pos)
+			}
+
+			tomlChild(node, parts[len(parts)-1], yaml.ScalarNode, pos)
+
+			for _, quotes := range []string{`"""`, "'''"} {
+				if strings.HasPrefix(value, quotes) && !strings.Contains(value[len(quotes):], quotes) {
+					multiLine = quotes
+				}
+			}
+
+			unclosed = tomlUnclosed(value)
+		}
+	}
+
+	return root
+}
+
+// decodeTOML decodes a TOML program description. The TOML decoder only gives the position of
+// errors, the positions of tables and keys come from reading the description again with tomlNode.
+// Unknown keys it can't locate, like the ones of inline tables, are reported without a position.
+func decodeTOML(content []byte, cli *CLIProgram) []*ValidationError {
+	metaData, err := toml.Decode(string(content), cli)
+	if err != nil {
+		syntaxError := &ValidationError{Rule: RuleSyntax, Message: err.Error(), Err: ErrParsingInputFile}
+
+		if match := tomlErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+			syntaxError.Position.Line, _ = strconv.Atoi(match[1])
+			syntaxError.Message = match[2]
+		}
+
+		var parseError toml.ParseError
+		if errors.As(err, &parseError) {
+			syntaxError.Position = offsetPosition(content, parseError.Position.Start)
+		}
+
+		return []*ValidationError{syntaxError}
+	}
+
+	errs := make([]*ValidationError, 0)
+	annotateNode(tomlNode(content), reflect.ValueOf(cli).Elem(), &errs)
+
+	located := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		located[err.Field] = true
+	}
+
+	for _, key := range metaData.Undecoded() {
+		if located[key[len(key)-1]] {
+			continue
+		}
+
+		errs = append(errs, &ValidationError{
+			Rule:    RuleUnknownKey,
+			Field:   key.String(),
+			Message: "unknown key",
+			Err:     ErrUnknownKey,
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,194 @@
+package shellcligen
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// clearPositions resets the positions set loading a program, so it can be compared with a literal.
+func clearPositions(options *[]CLIOption, positionals *[]Positional, commands *[]Command) {
+	for i := range *options {
+		(*options)[i].Position = Position{}
+		(*options)[i].keyPositions = nil
+	}
+
+	for i := range *positionals {
+		(*positionals)[i].Position = Position{}
+	}
+
+	for i := range *commands {
+		command := &(*commands)[i]
+		command.Position = Position{}
+		clearPositions(&command.Options, &command.Positionals, &command.Commands)
+	}
+}
+
+func Test_unmarshalCLIProgram_positions(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		content     string
+		format      string
+		option      Position
+		command     Position
+		positional  Position
+		description string
+	}
+
+	tests := []test{
+		{content: yamlTestProgram, format: FormatYAML, option: Position{8, 5}, command: Position{12, 5}, positional: Position{15, 9}, description: "yaml"},
+		{content: jsonTestProgram, format: FormatJSON, option: Position{6, 5}, command: Position{9, 5}, positional: Position{12, 23}, description: "json"},
+		{content: tomlTestProgram, format: FormatTOML, option: Position{10, 1}, command: Position{15, 1}, positional: Position{19, 1}, description: "toml"},
+	}
+
+	for _, tt := range tests {
+		cli := CLIProgram{}
+		if err := unmarshalCLIProgram([]byte(tt.content), tt.format, &cli); err != nil {
+			t.Fatalf("got=%v, want no error for %s", err, tt.description)
+		}
+
+		if got := cli.Options[1].Position; got != tt.option {
+			t.Errorf("got=%s, want=%s for the %s option", got, tt.option, tt.description)
+		}
+
+		if got := cli.Commands[0].Position; got != tt.command {
+			t.Errorf("got=%s, want=%s for the %s command", got, tt.command, tt.description)
+		}
+
+		if got := cli.Commands[0].Positionals[0].Position; got != tt.positional {
+			t.Errorf("got=%s, want=%s for the %s positional", got, tt.positional, tt.description)
+		}
+	}
+}
+
+func TestLoadFile_errors(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		fileName string
+		content  string
+		want     []string
+		err      error
+	}
+
+	tests := []test{
+		{
+			fileName: "typo.yml",
+			content:  "options:\n  - short_name: a\n    long_name: all\n    conflict_with: [b]\n    envv: ALL\n",
+			want: []string{
+				`typo.yml:4:5: option -a/--all: conflict_with: unknown key, did you mean "conflicts_with"?`,
+				`typo.yml:5:5: option -a/--all: envv: unknown key, did you mean "env"?`,
+			},
+			err: ErrUnknownKey,
+		},
+		{
+			fileName: "type.yml",
+			content:  "options:\n  - short_name: a\n    required: maybe\n",
+			want:     []string{"type.yml:3:5: cannot unmarshal !!str `maybe` into bool"},
+			err:      ErrParsingInputFile,
+		},
+		{
+			fileName: "typo.json",
+			content:  "{\n  \"help_mesage\": \"x\"\n}\n",
+			want:     []string{`typo.json:2:3: help_mesage: unknown key, did you mean "help_message"?`},
+			err:      ErrUnknownKey,
+		},
+		{
+			fileName: "type.json",
+			content:  "{\n  \"help_message\": 5\n}\n",
+			want:     []string{"type.json:2:20: cannot unmarshal number into Go struct field CLIProgram.help_message of type string"},
+			err:      ErrParsingInputFile,
+		},
+		{
+			fileName: "escape.json",
+			content:  "{\n  \"help_message\": \"a\\/b\",\n  \"help_mesage\": \"x\"\n}\n",
+			want:     []string{"escape.json: help_mesage: unknown key"},
+			err:      ErrUnknownKey,
+		},
+		{
+			fileName: "typo.toml",
+			content:  "[[options]]\nshort_name = \"a\"\nlong_nam = \"all\"\n",
+			want:     []string{`typo.toml:3:1: option -a: long_nam: unknown key, did you mean "long_name"?`},
+			err:      ErrUnknownKey,
+		},
+		{
+			fileName: "inline.toml",
+			content:  "help_message = \"\"\"\nlong_nam = 1\n\"\"\"\noptions = [\n  {short_name = \"a\", long_nam = \"all\"},\n]\n",
+			want:     []string{"inline.toml: options.long_nam: unknown key"},
+			err:      ErrUnknownKey,
+		},
+		{
+			fileName: "name.toml",
+			content:  "# fetches articles\n  name = \"../fetch\"\n",
+			want:     []string{`name.toml:2:3: name: invalid program name "../fetch", it must start with a letter, a digit or '_' and hold letters, digits, '_', '.' or '-'`},
+			err:      ErrInvalidProgramName,
+		},
+		{
+			fileName: "conflict.yml",
+			content:  "options:\n  - short_name: a\n    long_name: all\n    conflicts_with: [b]\n",
+			want:     []string{`conflict.yml:4:5: option -a/--all: conflicts_with: unknown option "b"`},
+			err:      ErrParsingConflictOptions,
+		},
+		{
+			fileName: "syntax.toml",
+			content:  "[[options]]\nshort_name = \n",
+			want:     []string{`syntax.toml:2:14: expected value but found '\n' instead`},
+			err:      ErrParsingInputFile,
+		},
+		{
+			fileName: "invalid.yml",
+			content:  "options:\n  - short_name: a\n    long_name: all\n  - short_name: bb\n    long_name: bytes\n",
+			want:     []string{"invalid.yml:4:5: option -bb/--bytes: short_name: short option names must be a single character"},
+			err:      ErrInvalidOptionName,
+		},
+	}
+
+	for _, tt := range tests {
+		fileName := t.TempDir() + "/" + tt.fileName
+		if err := os.WriteFile(fileName, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cli, err := LoadFile(fileName)
+		if err == nil {
+			err = cli.Validate()
+		}
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("got=%v, want=%v for %s", err, tt.err, tt.fileName)
+
+			continue
+		}
+
+		want := strings.Join(tt.want, "\n")
+		if got := strings.ReplaceAll(err.Error(), fileName, tt.fileName); got != want {
+			t.Errorf("got=[%s], want=[%s]", got, want)
+		}
+	}
+}
+
+func Test_closestKey(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		key  string
+		want string
+	}
+
+	known := []string{"long_name", "short_name", "conflicts_with", "env"}
+
+	tests := []test{
+		{key: "conflict_with", want: "conflicts_with"},
+		{key: "longname", want: "long_name"},
+		{key: "environment", want: ""},
+		{key: "env", want: "env"},
+	}
+
+	for _, tt := range tests {
+		if got := closestKey(tt.key, known); got != tt.want {
+			t.Errorf("got=%q, want=%q for %q", got, tt.want, tt.key)
+		}
+	}
+}
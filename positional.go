@@ -0,0 +1,244 @@
+package shellcligen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+func positionalVariableName(positional *Positional) string {
+	return fmt.Sprintf("%s_positional", sanitizeOptionName(strings.TrimSpace(positional.Name)))
+}
+
+// positionalMinCount returns how many values a variadic positional needs at least.
+func positionalMinCount(positional *Positional) int {
+	if positional.MinCount > 0 {
+		return positional.MinCount
+	}
+
+	if positional.Required {
+		return 1
+	}
+
+	return 0
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+func positionalUsage(positional *Positional) string {
+	name := fmt.Sprintf("<%s>", strings.TrimSpace(positional.Name))
+	if positional.Variadic {
+		name += "..."
+	}
+
+	if positional.Required || (positional.Variadic && positionalMinCount(positional) > 0) {
+		return name
+	}
+
+	return fmt.Sprintf("[%s]", name)
+}
+
+func positionalsUsage(cli *CLIProgram) string {
+	usages := make([]string, 0, len(cli.Positionals))
+
+	for i := range cli.Positionals {
+		usages = append(usages, positionalUsage(&cli.Positionals[i]))
+	}
+
+	return strings.Join(usages, " ")
+}
+
+func positionalDescription(positional *Positional) string {
+	notes := make([]string, 0)
+
+	if description := strings.TrimSpace(positional.Description); len(description) > 0 {
+		notes = append(notes, description)
+	}
+
+	if positional.Variadic {
+		switch {
+		case positional.MaxCount > 0:
+			notes = append(notes, fmt.Sprintf("(%d to %s)", positionalMinCount(positional), pluralize(positional.MaxCount, "value")))
+		case positionalMinCount(positional) > 0:
+			notes = append(notes, fmt.Sprintf("(at least %s)", pluralize(positionalMinCount(positional), "value")))
+		}
+	} else if positional.Required {
+		notes = append(notes, "(required)")
+	}
+
+	return strings.Join(notes, " ")
+}
+
+func variadicPositionalIndex(cli *CLIProgram) int {
+	for i := range cli.Positionals {
+		if cli.Positionals[i].Variadic {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// validatePositionals makes sure positional values can always be bound without ambiguity: there is
+// at most one variadic positional, optional positionals come last and counts are only given to the
+// variadic one.
+func validatePositionals(cli *CLIProgram, regex *regexp.Regexp) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+	names := make(map[string]bool)
+	variadicName, optionalName := "", ""
+	optionalPosition := Position{}
+
+	invalid := func(pos Position, format string, args ...interface{}) {
+		errs = append(errs, newValidationError(RuleInvalidPositional, ErrInvalidPositional, pos, "positionals", format, args...))
+	}
+
+	for i := range cli.Positionals {
+		positional := &cli.Positionals[i]
+		name := strings.TrimSpace(positional.Name)
+
+		switch {
+		case !regex.MatchString(name):
+			invalid(positional.Position, "invalid positional name %q", positional.Name)
+		case names[name]:
+			invalid(positional.Position, "positional name %q is already used", name)
+		}
+
+		names[name] = true
+
+		if positional.Variadic {
+			if len(variadicName) > 0 {
+				invalid(positional.Position, "<%s> and <%s> are both variadic, only one positional can be", variadicName, name)
+			}
+
+			variadicName = name
+
+			if positional.MinCount < 0 || positional.MaxCount < 0 {
+				invalid(positional.Position, "<%s> counts can not be negative", name)
+			} else if positional.MaxCount > 0 && positional.MaxCount < positionalMinCount(positional) {
+				invalid(positional.Position, "<%s> max_count is lower than its min_count", name)
+			}
+		} else if positional.MinCount != 0 || positional.MaxCount != 0 {
+			invalid(positional.Position, "<%s> has counts but only variadic positionals can", name)
+		}
+
+		if len(optionalName) > 0 {
+			invalid(positional.Position, "<%s> comes after the optional <%s>, optional positionals must come last", name, optionalName)
+		}
+
+		if !positional.Required && !positional.Variadic && len(optionalName) == 0 {
+			optionalName, optionalPosition = name, positional.Position
+		}
+	}
+
+	if len(variadicName) > 0 && len(optionalName) > 0 {
+		invalid(optionalPosition, "the optional <%s> can not be bound along with the variadic <%s>", optionalName, variadicName)
+	}
+
+	return errs
+}
+
+func missingPositionalCheck(count int, message string) string {
+	return fmt.Sprintf(`
+if [[ "${#}" -lt %d ]]; then
+    echo "%s" >&2
+    usage >&2
+    exit 2
+fi
+`, count, message)
+}
+
+func generateVariadicPositionals(cli *CLIProgram, variadicIndex int) string {
+	var positionalsSb strings.Builder
+
+	before := cli.Positionals[:variadicIndex]
+	variadic := &cli.Positionals[variadicIndex]
+	after := cli.Positionals[variadicIndex+1:]
+
+	for i := range before {
+		positionalsSb.WriteString(missingPositionalCheck(i+1,
+			fmt.Sprintf("missing required argument: <%s>", strings.TrimSpace(before[i].Name))))
+	}
+
+	for i := range after {
+		positionalsSb.WriteString(missingPositionalCheck(len(before)+i+1,
+			fmt.Sprintf("missing required argument: <%s>", strings.TrimSpace(after[i].Name))))
+	}
+
+	if minCount := positionalMinCount(variadic); minCount > 0 {
+		positionalsSb.WriteString(missingPositionalCheck(len(before)+len(after)+minCount,
+			fmt.Sprintf("expected at least %s", pluralize(minCount, fmt.Sprintf("<%s> argument", strings.TrimSpace(variadic.Name))))))
+	}
+
+	if variadic.MaxCount > 0 {
+		positionalsSb.WriteString(fmt.Sprintf(`
+if [[ "${#}" -gt %d ]]; then
+    echo "expected at most %s" >&2
+    usage >&2
+    exit 2
+fi
+`, len(before)+len(after)+variadic.MaxCount,
+			pluralize(variadic.MaxCount, fmt.Sprintf("<%s> argument", strings.TrimSpace(variadic.Name)))))
+	}
+
+	positionalsSb.WriteString("\n")
+
+	for i := range before {
+		positionalsSb.WriteString(fmt.Sprintf("%s=\"${%d}\"\n", positionalVariableName(&before[i]), i+1))
+	}
+
+	positionalsSb.WriteString(fmt.Sprintf("%s=(\"${@:%d:${#}-%d}\")\n",
+		positionalVariableName(variadic), len(before)+1, len(before)+len(after)))
+
+	for i := range after {
+		positionalsSb.WriteString(fmt.Sprintf("%s=\"${@: -%d:1}\"\n", positionalVariableName(&after[i]), len(after)-i))
+	}
+
+	return positionalsSb.String()
+}
+
+func generateFixedPositionals(cli *CLIProgram) string {
+	var positionalsSb strings.Builder
+
+	for i := range cli.Positionals {
+		if cli.Positionals[i].Required {
+			positionalsSb.WriteString(missingPositionalCheck(i+1,
+				fmt.Sprintf("missing required argument: <%s>", strings.TrimSpace(cli.Positionals[i].Name))))
+		}
+	}
+
+	positionalsSb.WriteString(fmt.Sprintf(`
+if [[ "${#}" -gt %d ]]; then
+    echo "too many arguments" >&2
+    usage >&2
+    exit 2
+fi
+
+`, len(cli.Positionals)))
+
+	for i := range cli.Positionals {
+		positionalsSb.WriteString(fmt.Sprintf("%s=\"${%d:-}\"\n", positionalVariableName(&cli.Positionals[i]), i+1))
+	}
+
+	return positionalsSb.String()
+}
+
+// generatePositionals generates the code binding the arguments left after the options to the
+// declared positionals. Positionals before the variadic one are taken from the front, the ones
+// after it from the back and the variadic one gets whatever is left in between.
+func generatePositionals(cli *CLIProgram) string {
+	if len(cli.Positionals) == 0 {
+		return ""
+	}
+
+	if variadicIndex := variadicPositionalIndex(cli); variadicIndex != -1 {
+		return generateVariadicPositionals(cli, variadicIndex)
+	}
+
+	return generateFixedPositionals(cli)
+}
@@ -0,0 +1,170 @@
+package shellcligen
+
+import (
+	"testing"
+)
+
+func Test_positionalUsage(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		positional Positional
+		want       string
+	}
+
+	tests := []test{
+		{positional: Positional{Name: "dest", Required: true}, want: "<dest>"},
+		{positional: Positional{Name: "dest"}, want: "[<dest>]"},
+		{positional: Positional{Name: "src", Variadic: true, Required: true}, want: "<src>..."},
+		{positional: Positional{Name: "src", Variadic: true, MinCount: 2}, want: "<src>..."},
+		{positional: Positional{Name: "src", Variadic: true}, want: "[<src>...]"},
+	}
+
+	for _, tt := range tests {
+		if got := positionalUsage(&tt.positional); got != tt.want {
+			t.Errorf("got=%s, want=%s", got, tt.want)
+		}
+	}
+}
+
+func Test_validatePositionals(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		positionals []Positional
+		want        bool
+	}
+
+	tests := []test{
+		{
+			positionals: []Positional{
+				{Name: "src", Variadic: true, Required: true},
+				{Name: "dest", Required: true},
+			},
+			want: true,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Required: true},
+				{Name: "dest"},
+			},
+			want: true,
+		},
+		// Optional positionals can not be followed by required ones.
+		{
+			positionals: []Positional{
+				{Name: "src"},
+				{Name: "dest", Required: true},
+			},
+			want: false,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Variadic: true},
+				{Name: "more", Variadic: true},
+			},
+			want: false,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Required: true},
+				{Name: "src", Required: true},
+			},
+			want: false,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Variadic: true, MinCount: 3, MaxCount: 2},
+			},
+			want: false,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Required: true, MaxCount: 2},
+			},
+			want: false,
+		},
+		{
+			positionals: []Positional{
+				{Name: "in@put", Required: true},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		cliProgram := CLIProgram{Positionals: tt.positionals}
+		if got := len(validatePositionals(&cliProgram, cliOptionRegex)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t for positionals %+v", got, tt.want, tt.positionals)
+		}
+	}
+}
+
+func Test_generatePositionals(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		positionals []Positional
+		want        string
+	}
+
+	tests := []test{
+		{
+			positionals: []Positional{
+				{Name: "src", Variadic: true, Required: true, MaxCount: 3},
+				{Name: "dest", Required: true},
+			},
+			want: `
+if [[ "${#}" -lt 1 ]]; then
+    echo "missing required argument: <dest>" >&2
+    usage >&2
+    exit 2
+fi
+
+if [[ "${#}" -lt 2 ]]; then
+    echo "expected at least 1 <src> argument" >&2
+    usage >&2
+    exit 2
+fi
+
+if [[ "${#}" -gt 4 ]]; then
+    echo "expected at most 3 <src> arguments" >&2
+    usage >&2
+    exit 2
+fi
+
+src_positional=("${@:1:${#}-1}")
+dest_positional="${@: -1:1}"
+`,
+		},
+		{
+			positionals: []Positional{
+				{Name: "src", Required: true},
+				{Name: "dest"},
+			},
+			want: `
+if [[ "${#}" -lt 1 ]]; then
+    echo "missing required argument: <src>" >&2
+    usage >&2
+    exit 2
+fi
+
+if [[ "${#}" -gt 2 ]]; then
+    echo "too many arguments" >&2
+    usage >&2
+    exit 2
+fi
+
+src_positional="${1:-}"
+dest_positional="${2:-}"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		cliProgram := CLIProgram{Positionals: tt.positionals}
+		if got := generatePositionals(&cliProgram); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package shellcligen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Markers of the regions of the script holding user code, kept when the script is generated again.
+const (
+	userCodeBeginMarker = "# BEGIN USER CODE"
+	userCodeEndMarker   = "# END USER CODE"
+)
+
+var ErrUserCodeRegion = errors.New("error user code region")
+
+// userCodeRegion is a region found in a script, begin and end are the lines of its markers.
+type userCodeRegion struct {
+	name       string
+	begin, end int
+}
+
+// userCode returns an empty region, rendered by the userCode function of script templates.
+func userCode(name string) string {
+	return fmt.Sprintf("%s %s\n%s\n", userCodeBeginMarker, name, userCodeEndMarker)
+}
+
+// findUserCodeRegions returns the regions of the lines of a script, in order. Regions can't be
+// nested, must be named and closed, and each name can be used once.
+func findUserCodeRegions(lines []string) ([]userCodeRegion, error) {
+	regions := make([]userCodeRegion, 0)
+	seen := make(map[string]bool)
+	open := -1
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == userCodeEndMarker:
+			if open == -1 {
+				return nil, fmt.Errorf("line %d: %s without %s: %w", i+1, userCodeEndMarker, userCodeBeginMarker, ErrUserCodeRegion)
+			}
+
+			regions[len(regions)-1].end = i
+			open = -1
+		case strings.HasPrefix(line, userCodeBeginMarker):
+			name := strings.TrimSpace(strings.TrimPrefix(line, userCodeBeginMarker))
+
+			switch {
+			case open != -1:
+				return nil, fmt.Errorf("line %d: region %s begins before region %s of line %d ends: %w",
+					i+1, name, regions[len(regions)-1].name, open+1, ErrUserCodeRegion)
+			case len(name) == 0 || strings.ContainsAny(name, " \t"):
+				return nil, fmt.Errorf("line %d: invalid region name %q: %w", i+1, name, ErrUserCodeRegion)
+			case seen[name]:
+				return nil, fmt.Errorf("line %d: region %s found twice: %w", i+1, name, ErrUserCodeRegion)
+			}
+
+			seen[name] = true
+			open = i
+			regions = append(regions, userCodeRegion{name: name, begin: i})
+		}
+	}
+
+	if open != -1 {
+		return nil, fmt.Errorf("line %d: region %s without %s: %w", open+1, regions[len(regions)-1].name,
+			userCodeEndMarker, ErrUserCodeRegion)
+	}
+
+	return regions, nil
+}
+
+// mergeUserCode returns the generated script with the user code of the regions of the existing one,
+// regions new to the generated script are left empty. An error is returned when the markers of the
+// existing script are corrupted or when the generated script no longer has one of its regions.
+// Existing scripts with no regions at all are replaced when they were generated before regions
+// existed, when they have a generated header their regions were removed and an error is returned.
+func mergeUserCode(generated, existing string) (string, error) {
+	existingLines := strings.SplitAfter(existing, "\n")
+
+	existingRegions, err := findUserCodeRegions(existingLines)
+	if err != nil {
+		return "", err
+	}
+
+	generatedLines := strings.SplitAfter(generated, "\n")
+
+	generatedRegions, err := findUserCodeRegions(generatedLines)
+	if err != nil {
+		return "", err
+	}
+
+	if len(existingRegions) == 0 {
+		if len(generatedRegions) > 0 && generatedHeaderRegex.MatchString(existing) {
+			return "", fmt.Errorf("region %s was removed, add its markers back or delete the script to generate it again: %w",
+				generatedRegions[0].name, ErrUserCodeRegion)
+		}
+
+		return generated, nil
+	}
+
+	code := make(map[string]string, len(existingRegions))
+	for _, region := range existingRegions {
+		code[region.name] = strings.Join(existingLines[region.begin+1:region.end], "")
+	}
+
+	var scriptSb strings.Builder
+
+	next := 0
+
+	for _, region := range generatedRegions {
+		regionCode, ok := code[region.name]
+		if !ok {
+			continue
+		}
+
+		delete(code, region.name)
+
+		scriptSb.WriteString(strings.Join(generatedLines[next:region.begin+1], ""))
+		scriptSb.WriteString(regionCode)
+
+		next = region.end
+	}
+
+	for _, region := range existingRegions {
+		if _, ok := code[region.name]; ok {
+			return "", fmt.Errorf("region %s is no longer generated, its code would be lost: %w", region.name, ErrUserCodeRegion)
+		}
+	}
+
+	scriptSb.WriteString(strings.Join(generatedLines[next:], ""))
+
+	return scriptSb.String(), nil
+}
@@ -0,0 +1,78 @@
+package shellcligen
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_mergeUserCode(t *testing.T) {
+	t.Parallel()
+
+	generated := "#!/bin/bash\nparse_v2\n# BEGIN USER CODE setup\n# END USER CODE\n\n# BEGIN USER CODE main\n# END USER CODE\n"
+
+	type test struct {
+		existing string
+		want     string
+		err      error
+	}
+
+	tests := []test{
+		{
+			existing: "#!/bin/bash\nparse_v1\n# BEGIN USER CODE setup\ncd /tmp\n# END USER CODE\n\n# BEGIN USER CODE main\n  run \"${@}\"\n\n# END USER CODE\n",
+			want:     "#!/bin/bash\nparse_v2\n# BEGIN USER CODE setup\ncd /tmp\n# END USER CODE\n\n# BEGIN USER CODE main\n  run \"${@}\"\n\n# END USER CODE\n",
+		},
+		{
+			existing: "#!/bin/bash\nparse_v1\n  # BEGIN USER CODE main\nrun\n  # END USER CODE\n# BEGIN USER CODE setup\n# END USER CODE\n",
+			want:     "#!/bin/bash\nparse_v2\n# BEGIN USER CODE setup\n# END USER CODE\n\n# BEGIN USER CODE main\nrun\n# END USER CODE\n",
+		},
+		{
+			existing: "#!/bin/bash\nparse_v1\nrun\n",
+			want:     generated,
+		},
+		{
+			existing: "# BEGIN USER CODE main\nrun\n",
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: "run\n# END USER CODE\n",
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: "# BEGIN USER CODE main\n# BEGIN USER CODE setup\n# END USER CODE\n",
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: "# BEGIN USER CODE\n# END USER CODE\n",
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: "# BEGIN USER CODE main\n# END USER CODE\n# BEGIN USER CODE main\n# END USER CODE\n",
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: "# BEGIN USER CODE main\nrun\n# END USER CODE\n",
+			want:     "#!/bin/bash\nparse_v2\n# BEGIN USER CODE setup\n# END USER CODE\n\n# BEGIN USER CODE main\nrun\n# END USER CODE\n",
+		},
+		{
+			existing: withGeneratedHeader(scriptFileName, "#!/bin/bash\nparse_v1\nrun\n"),
+			err:      ErrUserCodeRegion,
+		},
+		{
+			existing: generated + "# BEGIN USER CODE cleanup\nrm -f lock\n# END USER CODE\n",
+			err:      ErrUserCodeRegion,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := mergeUserCode(generated, tt.existing)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("got=%v, want=%v merging [%s]", err, tt.err, tt.existing)
+
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package shellcligen
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+const specHeaderComment = `Spec of a program, generate its script with: shellcligen generate -output <dir> <this file>`
+
+// specKeyComments explain the keys of a program, written above them.
+var specKeyComments = map[string]string{
+	"name":         "File name of the script, its configuration file is <name>.conf. script.sh when empty.",
+	"help_message": "Shown at the top of the usage of the script.",
+	"safe_flags":   "Runs the script with set -euo pipefail.",
+	"options": `Options of the script, each one needs a short and a long name. Options also accept:
+  type: string, int, float, bool, enum, file, dir or path, the value is checked against it
+  choices: the values accepted by an enum option
+  default: the value used when the option is not given
+  env: the environment variable the value can be read from`,
+	"positionals": "Arguments given after the options.",
+	"commands":    "Subcommands of the script, with their own options, positionals and commands.",
+}
+
+// specOptionComments explain the keys of an option, written next to them in the first option.
+var specOptionComments = map[string]string{
+	"long_name":      "given as --<long_name> on the command line",
+	"short_name":     "given as -<short_name> on the command line",
+	"required":       "the script fails when the option is not given",
+	"args_required":  "the option takes a value",
+	"conflicts_with": "names of the options which can not be given along with this one",
+	"is_help":        "prints the usage and exits",
+	"description":    "shown next to the option in the usage",
+}
+
+// mappingValue returns the value of a key of a mapping node, nil if the key is not there.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// MarshalCommentedCLIProgram returns the program as YAML, like MarshalCLIProgram, with comments
+// explaining its keys. It is meant for specs people start editing from.
+func MarshalCommentedCLIProgram(cli *CLIProgram) ([]byte, error) {
+	program := yaml.Node{}
+	if err := program.Encode(cli); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(program.Content); i += 2 {
+		program.Content[i].HeadComment = specKeyComments[program.Content[i].Value]
+	}
+
+	if options := mappingValue(&program, "options"); options != nil && len(options.Content) > 0 {
+		firstOption := options.Content[0]
+
+		for i := 0; i+1 < len(firstOption.Content); i += 2 {
+			firstOption.Content[i].LineComment = specOptionComments[firstOption.Content[i].Value]
+		}
+	}
+
+	document := yaml.Node{Kind: yaml.DocumentNode, HeadComment: specHeaderComment, Content: []*yaml.Node{&program}}
+
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,40 @@
+package shellcligen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalCommentedCLIProgram(t *testing.T) {
+	t.Parallel()
+
+	want := commandsTestProgram()
+
+	content, err := MarshalCommentedCLIProgram(&want)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	for _, comment := range []string{
+		"# " + specHeaderComment + "\n",
+		"# " + specKeyComments["options"][:30],
+		"long_name: verbose # " + specOptionComments["long_name"] + "\n",
+		"# " + specKeyComments["commands"] + "\ncommands:",
+	} {
+		if !strings.Contains(string(content), comment) {
+			t.Errorf("got=[%s], want it to contain [%s]", content, comment)
+		}
+	}
+
+	got := CLIProgram{}
+	if err := unmarshalCLIProgram(content, FormatYAML, &got); err != nil {
+		t.Fatalf("got=%v, want no error reading it back", err)
+	}
+
+	clearPositions(&got.Options, &got.Positionals, &got.Commands)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got=%+v, want=%+v", got, want)
+	}
+}
@@ -3,13 +3,8 @@ package shellcligen
 import (
 	"errors"
 	"fmt"
-	"io"
-	"os"
-	"path"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -21,8 +16,16 @@ var (
 	ErrInvalidOptionName       = errors.New("error invalid option name")
 	ErrCreatingOutputProgram   = errors.New("error creating output script")
 	ErrRepeatedOptionNames     = errors.New("error repeated option names")
+	ErrInvalidOptionType       = errors.New("error invalid option type")
+	ErrInvalidDefaultValue     = errors.New("error invalid default value")
+	ErrInvalidEnvironmentName  = errors.New("error invalid environment variable name")
+	ErrInvalidPositional       = errors.New("error invalid positional argument")
+	ErrInvalidCommand          = errors.New("error invalid command")
+	ErrInvalidProgramName      = errors.New("error invalid program name")
 
 	cliOptionRegex = regexp.MustCompile("^[a-zA-Z_]([a-zA-Z0-9_]*)$")
+	envNameRegex   = regexp.MustCompile("^[a-zA-Z_]([a-zA-Z0-9_]*)$")
+	programRegex   = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_.\-]*)$`)
 )
 
 func isOptionNameValid(optionName string, rgx *regexp.Regexp) bool {
@@ -43,22 +46,10 @@ func existInArrayName(name string, arr *[]Name) bool {
 	return exist
 }
 
-func hasTheOption(options *[]string, names *[]Name) bool {
-	exists := false
-
-	for _, option := range *options {
-		if existInArrayName(option, names) {
-			exists = true
-
-			break
-		}
-	}
-
-	return exists
-}
-
-func validateExistingConflictingOptionNames(cli *CLIProgram) bool {
-	valid := true
+// validateExistingConflictingOptionNames checks the options declared from firstOwnOption on only
+// conflict with options of the program, the ones before it being inherited ones.
+func validateExistingConflictingOptionNames(cli *CLIProgram, firstOwnOption int) []*ValidationError {
+	errs := make([]*ValidationError, 0)
 
 	optionNames := make([]Name, 0)
 
@@ -69,81 +60,98 @@ func validateExistingConflictingOptionNames(cli *CLIProgram) bool {
 		})
 	}
 
-	for _, opt := range cli.Options {
-		if len(opt.ConflictsWith) > 0 {
-			if !hasTheOption(&opt.ConflictsWith, &optionNames) {
-				valid = false
-
-				break
+	for i := firstOwnOption; i < len(cli.Options); i++ {
+		for _, conflictName := range cli.Options[i].ConflictsWith {
+			if !existInArrayName(strings.TrimSpace(conflictName), &optionNames) {
+				errs = append(errs, newOptionError(RuleUnknownConflict, ErrParsingConflictOptions, &cli.Options[i],
+					"conflicts_with", "unknown option %q", conflictName))
 			}
 		}
 	}
 
-	return valid
+	return errs
 }
 
-func validateCLIOptionNames(cli *CLIProgram, regex *regexp.Regexp) bool {
-	valid := true
+// validateProgramName checks the name of the program can be used as a file name.
+func validateProgramName(cli *CLIProgram, regex *regexp.Regexp) []*ValidationError {
+	if len(cli.Name) == 0 || regex.MatchString(cli.Name) {
+		return nil
+	}
 
-	for _, opt := range cli.Options {
-		if !isOptionNameValid(opt.LongName, regex) || !isOptionNameValid(opt.ShortName, regex) {
-			valid = false
+	return []*ValidationError{newValidationError(RuleInvalidProgramName, ErrInvalidProgramName, cli.namePosition, "name",
+		"invalid program name %q, it must start with a letter, a digit or '_' and hold letters, digits, '_', '.' or '-'", cli.Name)}
+}
 
-			break
+func validateCLIOptionNames(cli *CLIProgram, regex *regexp.Regexp) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	for i := range cli.Options {
+		opt := &cli.Options[i]
+
+		if !isOptionNameValid(opt.ShortName, regex) {
+			errs = append(errs, newOptionError(RuleInvalidOptionName, ErrInvalidOptionName, opt,
+				"short_name", "invalid option name %q", opt.ShortName))
+		}
+
+		if !isOptionNameValid(opt.LongName, regex) {
+			errs = append(errs, newOptionError(RuleInvalidOptionName, ErrInvalidOptionName, opt,
+				"long_name", "invalid option name %q", opt.LongName))
 		}
 	}
 
-	return valid
+	return errs
 }
 
-func haveRepeatedElements(cliOptionNamesCount *map[string]int) bool {
-	for _, count := range *cliOptionNamesCount {
-		if count > 1 {
-			return true
+func validateShortOptionNamesLength(cli *CLIProgram) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	for i := range cli.Options {
+		if len(strings.TrimSpace(cli.Options[i].ShortName)) > 1 {
+			errs = append(errs, newOptionError(RuleShortOptionNameLength, ErrInvalidOptionName, &cli.Options[i],
+				"short_name", "short option names must be a single character"))
 		}
 	}
 
-	return false
+	return errs
 }
 
-func validateUniqueCLIOptionNamesCount(cliOptions *[]CLIOption) bool {
-	shortCLIOptionNamesCount := make(map[string]int)
-	longCLIOptionNamesCount := make(map[string]int)
-
-	for _, cliOption := range *cliOptions {
-		if len(cliOption.LongName) != 0 {
-			longCLIOptionNamesCount[cliOption.LongName]++
-		}
+func validateEnvironmentNames(cli *CLIProgram, regex *regexp.Regexp) []*ValidationError {
+	errs := make([]*ValidationError, 0)
 
-		if len(cliOption.ShortName) != 0 {
-			shortCLIOptionNamesCount[cliOption.ShortName]++
+	for i := range cli.Options {
+		env := strings.TrimSpace(cli.Options[i].Env)
+		if len(env) > 0 && !regex.MatchString(env) {
+			errs = append(errs, newOptionError(RuleInvalidEnvironmentName, ErrInvalidEnvironmentName, &cli.Options[i],
+				"env", "invalid environment variable name %q", env))
 		}
 	}
 
-	return !haveRepeatedElements(&shortCLIOptionNamesCount) && !haveRepeatedElements(&longCLIOptionNamesCount)
+	return errs
 }
 
-func createCliProgramScript(cli *CLIProgram, outputDirectory string) error {
-	outputScriptFile, err := os.Create(path.Join(outputDirectory, scriptFileName))
-	if err != nil {
-		return err
-	}
-	defer outputScriptFile.Close()
+// validateUniqueCLIOptionNamesCount reports the options declared from firstOwnOption on reusing
+// the name of an option declared before them.
+func validateUniqueCLIOptionNamesCount(cliOptions *[]CLIOption, firstOwnOption int) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+	shortCLIOptionNames := make(map[string]bool)
+	longCLIOptionNames := make(map[string]bool)
+
+	for i, cliOption := range *cliOptions {
+		if i >= firstOwnOption && len(cliOption.ShortName) != 0 && shortCLIOptionNames[cliOption.ShortName] {
+			errs = append(errs, newOptionError(RuleRepeatedOptionName, ErrRepeatedOptionNames, &(*cliOptions)[i],
+				"short_name", "option name %q is already used", cliOption.ShortName))
+		}
 
-	outputScriptConfFile, err := os.Create(path.Join(outputDirectory, scriptConfigFileName))
-	if err != nil {
-		return err
-	}
-	defer outputScriptConfFile.Close()
+		if i >= firstOwnOption && len(cliOption.LongName) != 0 && longCLIOptionNames[cliOption.LongName] {
+			errs = append(errs, newOptionError(RuleRepeatedOptionName, ErrRepeatedOptionNames, &(*cliOptions)[i],
+				"long_name", "option name %q is already used", cliOption.LongName))
+		}
 
-	template := templateWithConflictChecking
-	if cli.SafeFlags {
-		template = strings.ReplaceAll(template, safeFlagsTemplateTag, safeFlagsTemplate)
+		shortCLIOptionNames[cliOption.ShortName] = true
+		longCLIOptionNames[cliOption.LongName] = true
 	}
 
-	_, _ = outputScriptFile.WriteString(template)
-
-	return nil
+	return errs
 }
 
 func hasRequiredOptions(cliProgram *CLIProgram) bool {
@@ -181,14 +189,27 @@ func flagOptionName(clIOption *CLIOption) string {
 	return fmt.Sprintf("%s_option_flag", sanitizeOptionName(name))
 }
 
+func argOptionName(cliOption *CLIOption) string {
+	name := optionName(cliOption)
+
+	return fmt.Sprintf("%s_arg", sanitizeOptionName(name))
+}
+
 func generateCaseArsCode(cliOption *CLIOption, switchCaseSb *strings.Builder) {
-	if cliOption.ArgsRequired {
-		name := optionName(cliOption)
-		name = sanitizeOptionName(name)
-		switchCaseSb.WriteString(fmt.Sprintf(`%s_arg+=("${2}")`, name))
+	if !cliOption.ArgsRequired {
+		switchCaseSb.WriteString("shift\n")
+
+		return
+	}
+
+	if validationCall := typeValidationCall(cliOption, "${2}"); len(validationCall) > 0 {
+		switchCaseSb.WriteString(validationCall)
 		switchCaseSb.WriteString("\n")
-		switchCaseSb.WriteString("shift 2\n")
 	}
+
+	switchCaseSb.WriteString(fmt.Sprintf(`%s+=("${2}")`, argOptionName(cliOption)))
+	switchCaseSb.WriteString("\n")
+	switchCaseSb.WriteString("shift 2\n")
 }
 
 func generateSwitchCaseFromCLIOption(cliOption *CLIOption) string {
@@ -199,57 +220,535 @@ func generateSwitchCaseFromCLIOption(cliOption *CLIOption) string {
 	flagOption := flagOptionName(cliOption)
 
 	if len(shortOptionName) > 0 && len(longOptionName) > 0 {
-		switchCaseSb.WriteString(fmt.Sprintf("%s|%s)\n", shortOptionName, longOptionName))
+		switchCaseSb.WriteString(fmt.Sprintf("-%s|--%s)\n", shortOptionName, longOptionName))
 	} else if len(shortOptionName) > 0 && len(longOptionName) == 0 {
-		switchCaseSb.WriteString(fmt.Sprintf("%s)\n", shortOptionName))
+		switchCaseSb.WriteString(fmt.Sprintf("-%s)\n", shortOptionName))
 	} else if len(shortOptionName) == 0 && len(longOptionName) > 0 {
-		switchCaseSb.WriteString(fmt.Sprintf("%s)\n", longOptionName))
+		switchCaseSb.WriteString(fmt.Sprintf("--%s)\n", longOptionName))
 	}
 
 	switchCaseSb.WriteString(fmt.Sprintf("%s=1\n", flagOption))
-	generateCaseArsCode(cliOption, &switchCaseSb)
+
+	if cliOption.Help {
+		switchCaseSb.WriteString("usage\n")
+		switchCaseSb.WriteString("exit 0\n")
+	} else {
+		generateCaseArsCode(cliOption, &switchCaseSb)
+	}
 
 	switchCaseSb.WriteString(";;\n")
 
 	return switchCaseSb.String()
 }
 
-// ParseCLIProgram ...
-func ParseCLIProgram(configFile, outputDirectory string) (CLIProgram, error) {
-	file, err := os.Open(configFile)
-	if err != nil {
-		return CLIProgram{}, fmt.Errorf("error opening input file: %w", ErrOpeningInputFile)
+func indentCaseArm(caseArm string) string {
+	var indentedSb strings.Builder
+
+	lines := strings.Split(strings.TrimSuffix(caseArm, "\n"), "\n")
+	for i, line := range lines {
+		indent := "        "
+		if i == 0 {
+			indent = "    "
+		}
+
+		indentedSb.WriteString(indent + line + "\n")
 	}
 
-	defer file.Close()
+	return indentedSb.String()
+}
 
-	cli := CLIProgram{}
+func generateCaseArms(cli *CLIProgram) string {
+	var caseArmsSb strings.Builder
 
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		return CLIProgram{}, fmt.Errorf("error reading input file: %w", ErrReadingInputFile)
+	for i := range cli.Options {
+		caseArmsSb.WriteString(indentCaseArm(generateSwitchCaseFromCLIOption(&cli.Options[i])))
 	}
 
-	err = yaml.Unmarshal(fileContent, &cli)
-	if err != nil {
-		return CLIProgram{}, fmt.Errorf("error parsing input file: %w", err)
+	return caseArmsSb.String()
+}
+
+// generateOptionFlagsInit initializes the variables of the options of every scope up front, the
+// options of sibling commands may share variables since only one of them runs.
+func generateOptionFlagsInit(cli *CLIProgram) string {
+	var flagsSb strings.Builder
+
+	if len(cli.Commands) > 0 {
+		flagsSb.WriteString("command_path=\"\"\n")
+		flagsSb.WriteString("command_args=()\n")
+	}
+
+	initialized := make(map[string]bool)
+
+	for _, cliOption := range allScopesOptions(cli) {
+		if initialized[flagOptionName(cliOption)] {
+			continue
+		}
+
+		initialized[flagOptionName(cliOption)] = true
+
+		flagsSb.WriteString(fmt.Sprintf("%s=0\n", flagOptionName(cliOption)))
+
+		if cliOption.ArgsRequired {
+			flagsSb.WriteString(fmt.Sprintf("%s=()\n", argOptionName(cliOption)))
+		}
+	}
+
+	return flagsSb.String()
+}
+
+func getoptShortOptions(cli *CLIProgram) string {
+	var shortOptionsSb strings.Builder
+
+	// Stop at the first non-option argument, it is the command to run.
+	if len(cli.Commands) > 0 {
+		shortOptionsSb.WriteString("+")
+	}
+
+	for _, opt := range cli.Options {
+		shortOptionName := strings.TrimSpace(opt.ShortName)
+		if len(shortOptionName) == 0 {
+			continue
+		}
+
+		shortOptionsSb.WriteString(shortOptionName)
+
+		if opt.ArgsRequired {
+			shortOptionsSb.WriteString(":")
+		}
+	}
+
+	return shortOptionsSb.String()
+}
+
+func getoptLongOptions(cli *CLIProgram) string {
+	longOptions := make([]string, 0, len(cli.Options))
+
+	for _, opt := range cli.Options {
+		longOptionName := strings.TrimSpace(opt.LongName)
+		if len(longOptionName) == 0 {
+			continue
+		}
+
+		if opt.ArgsRequired {
+			longOptionName += ":"
+		}
+
+		longOptions = append(longOptions, longOptionName)
+	}
+
+	return strings.Join(longOptions, ",")
+}
+
+func generateGetoptCall(cli *CLIProgram) string {
+	return fmt.Sprintf(`getopt --name "$(basename "${0}")" --options '%s' --long '%s'`,
+		getoptShortOptions(cli), getoptLongOptions(cli))
+}
+
+func displayOptionName(cliOption *CLIOption) string {
+	shortOptionName := strings.TrimSpace(cliOption.ShortName)
+	longOptionName := strings.TrimSpace(cliOption.LongName)
+
+	switch {
+	case len(shortOptionName) > 0 && len(longOptionName) > 0:
+		return fmt.Sprintf("-%s/--%s", shortOptionName, longOptionName)
+	case len(shortOptionName) > 0:
+		return "-" + shortOptionName
+	default:
+		return "--" + longOptionName
+	}
+}
+
+func findOptionIndex(name string, cliOptions []CLIOption) int {
+	name = strings.TrimSpace(name)
+
+	for i, opt := range cliOptions {
+		if strings.TrimSpace(opt.ShortName) == name || strings.TrimSpace(opt.LongName) == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// conflictingOptionPairs returns every pair of conflicting option indexes once, treating
+// a conflict declared on either side as symmetric.
+func conflictingOptionPairs(cli *CLIProgram) [][2]int {
+	pairs := make([][2]int, 0)
+	seen := make(map[[2]int]bool)
+
+	for i, opt := range cli.Options {
+		for _, conflictName := range opt.ConflictsWith {
+			j := findOptionIndex(conflictName, cli.Options)
+			if j == -1 || j == i {
+				continue
+			}
+
+			pair := [2]int{i, j}
+			if j < i {
+				pair = [2]int{j, i}
+			}
+
+			if seen[pair] {
+				continue
+			}
+
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+func generateRequiredOptionsCheck(cli *CLIProgram) string {
+	var requiredSb strings.Builder
+
+	for i := range cli.Options {
+		cliOption := &cli.Options[i]
+		if !cliOption.Required {
+			continue
+		}
+
+		requiredSb.WriteString(fmt.Sprintf(`
+if [[ "${%s}" -eq 0 ]]; then
+    echo "missing required option: %s" >&2
+    exit 2
+fi
+`, flagOptionName(cliOption), displayOptionName(cliOption)))
+	}
+
+	return requiredSb.String()
+}
+
+// generateConflictingOptionsCheck checks the conflicts involving the options from firstOwnOption
+// onwards, the ones before it are inherited and their conflicts are checked by their own scope.
+func generateConflictingOptionsCheck(cli *CLIProgram, firstOwnOption int) string {
+	var conflictsSb strings.Builder
+
+	for _, pair := range conflictingOptionPairs(cli) {
+		if pair[1] < firstOwnOption {
+			continue
+		}
+
+		first, second := &cli.Options[pair[0]], &cli.Options[pair[1]]
+
+		conflictsSb.WriteString(fmt.Sprintf(`
+if [[ "${%s}" -eq 1 && "${%s}" -eq 1 ]]; then
+    echo "option %s conflicts with option %s" >&2
+    exit 2
+fi
+`, flagOptionName(first), flagOptionName(second), displayOptionName(first), displayOptionName(second)))
+	}
+
+	return conflictsSb.String()
+}
+
+func usageOptionColumn(cliOption *CLIOption) string {
+	shortOptionName := strings.TrimSpace(cliOption.ShortName)
+	longOptionName := strings.TrimSpace(cliOption.LongName)
+
+	column := "    "
+	if len(shortOptionName) > 0 {
+		column = fmt.Sprintf("-%s", shortOptionName)
+		if len(longOptionName) > 0 {
+			column += ", "
+		}
+	}
+
+	if len(longOptionName) > 0 {
+		column += fmt.Sprintf("--%s", longOptionName)
 	}
 
-	if !validateExistingConflictingOptionNames(&cli) {
-		return CLIProgram{}, fmt.Errorf("error with conflicting options: %w", ErrParsingConflictOptions)
+	if cliOption.ArgsRequired {
+		column += " " + argPlaceholder(cliOption)
 	}
 
-	if !validateCLIOptionNames(&cli, cliOptionRegex) {
-		return CLIProgram{}, fmt.Errorf("error invalid option name: %w", ErrInvalidOptionName)
+	return column
+}
+
+// conflictingOptionNames returns the display names of the options the option conflicts with.
+func conflictingOptionNames(cli *CLIProgram, optionIndex int) []string {
+	conflicts := make([]string, 0)
+
+	for _, pair := range conflictingOptionPairs(cli) {
+		switch optionIndex {
+		case pair[0]:
+			conflicts = append(conflicts, displayOptionName(&cli.Options[pair[1]]))
+		case pair[1]:
+			conflicts = append(conflicts, displayOptionName(&cli.Options[pair[0]]))
+		}
 	}
 
-	if !validateUniqueCLIOptionNamesCount(&cli.Options) {
-		return CLIProgram{}, fmt.Errorf("error repeated option names: %w", ErrRepeatedOptionNames)
+	return conflicts
+}
+
+func usageDescriptionColumn(cli *CLIProgram, optionIndex int) string {
+	cliOption := &cli.Options[optionIndex]
+	notes := make([]string, 0)
+
+	if description := strings.TrimSpace(cliOption.Description); len(description) > 0 {
+		notes = append(notes, description)
+	}
+
+	if cliOption.Required {
+		notes = append(notes, "(required)")
+	}
+
+	if len(cliOption.Default) > 0 {
+		notes = append(notes, fmt.Sprintf("(default: %s)", cliOption.Default))
+	}
+
+	if env := strings.TrimSpace(cliOption.Env); len(env) > 0 {
+		notes = append(notes, fmt.Sprintf("(env: %s)", env))
+	}
+
+	if conflicts := conflictingOptionNames(cli, optionIndex); len(conflicts) > 0 {
+		notes = append(notes, fmt.Sprintf("(conflicts with %s)", strings.Join(conflicts, ", ")))
+	}
+
+	return strings.Join(notes, " ")
+}
+
+func usageLine(scope *commandScope) string {
+	return "Usage: " + scopeSynopsis(scope, "$(basename \"${0}\")")
+}
+
+// scopeSynopsis returns how the scope is invoked, programName being how the script is called.
+func scopeSynopsis(scope *commandScope, programName string) string {
+	line := programName
+	if len(scope.path) > 0 {
+		line += " " + commandPath(scope)
+	}
+
+	line += " [OPTIONS]"
+
+	if len(scope.cli.Commands) > 0 {
+		line += " <command> [ARGS]"
+	}
+
+	if len(scope.cli.Positionals) > 0 {
+		line += " " + positionalsUsage(&scope.cli)
+	}
+
+	return line
+}
+
+func writeUsageTable(usageSb *strings.Builder, title string, width int, columns, descriptions []string) {
+	if len(columns) == 0 {
+		return
+	}
+
+	usageSb.WriteString(fmt.Sprintf("\n%s:\n", title))
+
+	for i := range columns {
+		line := fmt.Sprintf("  %-*s  %s", width, columns[i], descriptions[i])
+		usageSb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
+
+func maxWidth(width int, columns []string) int {
+	for _, column := range columns {
+		if len(column) > width {
+			width = len(column)
+		}
+	}
+
+	return width
+}
+
+// generateUsageBody generates the statements printing the usage of a scope, the options it inherits
+// are listed apart as global options.
+func generateUsageBody(scope *commandScope) string {
+	var usageSb strings.Builder
+
+	program := scopeProgram(scope)
+	commandColumns, commandDescriptions := make([]string, 0), make([]string, 0)
+	positionalColumns, positionalDescriptions := make([]string, 0), make([]string, 0)
+	optionColumns, optionDescriptions := make([]string, 0), make([]string, 0)
+	inheritedColumns, inheritedDescriptions := make([]string, 0), make([]string, 0)
+
+	for _, command := range scope.cli.Commands {
+		commandColumns = append(commandColumns, strings.TrimSpace(command.Name))
+		commandDescriptions = append(commandDescriptions, firstHelpLine(command.Help))
 	}
 
-	if err = createCliProgramScript(&cli, outputDirectory); err != nil {
-		return CLIProgram{}, fmt.Errorf("error creating output script: %w", ErrCreatingOutputProgram)
+	for i := range scope.cli.Positionals {
+		positionalColumns = append(positionalColumns, positionalUsage(&scope.cli.Positionals[i]))
+		positionalDescriptions = append(positionalDescriptions, positionalDescription(&scope.cli.Positionals[i]))
+	}
+
+	for i := range program.Options {
+		column, description := usageOptionColumn(&program.Options[i]), usageDescriptionColumn(&program, i)
+		if i < len(scope.inherited) {
+			inheritedColumns = append(inheritedColumns, column)
+			inheritedDescriptions = append(inheritedDescriptions, description)
+		} else {
+			optionColumns = append(optionColumns, column)
+			optionDescriptions = append(optionDescriptions, description)
+		}
+	}
+
+	width := maxWidth(maxWidth(maxWidth(maxWidth(0, commandColumns), positionalColumns), optionColumns), inheritedColumns)
+
+	usageSb.WriteString(fmt.Sprintf("    echo \"%s\"\n", usageLine(scope)))
+	usageSb.WriteString("    cat <<'END_OF_USAGE'\n")
+
+	if help := strings.TrimSpace(scope.cli.Help); len(help) > 0 {
+		usageSb.WriteString("\n" + help + "\n")
+	}
+
+	writeUsageTable(&usageSb, "Commands", width, commandColumns, commandDescriptions)
+	writeUsageTable(&usageSb, "Arguments", width, positionalColumns, positionalDescriptions)
+	writeUsageTable(&usageSb, "Options", width, optionColumns, optionDescriptions)
+	writeUsageTable(&usageSb, "Global options", width, inheritedColumns, inheritedDescriptions)
+
+	if len(configurableOptions(&program)) > 0 {
+		usageSb.WriteString(fmt.Sprintf(`
+Option values are taken from the command line first, then from their environment
+variables, then from %s and finally from their default values.
+`, configFileName(&scope.cli)))
+	}
+
+	usageSb.WriteString("END_OF_USAGE\n")
+
+	return usageSb.String()
+}
+
+// generateUsage generates the usage function, programs with commands get a usage function per
+// command and usage prints the one of the invoked command.
+func generateUsage(cli *CLIProgram) string {
+	scopes := commandScopes(cli)
+	if len(scopes) == 1 {
+		return "usage() {\n" + generateUsageBody(&scopes[0]) + "}\n"
+	}
+
+	var usageSb strings.Builder
+
+	for i := range scopes {
+		usageSb.WriteString(fmt.Sprintf("%s() {\n%s}\n\n", usageFunctionName(&scopes[i]), generateUsageBody(&scopes[i])))
+	}
+
+	usageSb.WriteString("usage() {\n")
+	usageSb.WriteString("    case \"${command_path}\" in\n")
+
+	for i := 1; i < len(scopes); i++ {
+		usageSb.WriteString(fmt.Sprintf("    \"%s\")\n        %s\n        ;;\n", commandPath(&scopes[i]), usageFunctionName(&scopes[i])))
+	}
+
+	usageSb.WriteString(fmt.Sprintf("    *)\n        %s\n        ;;\n", usageFunctionName(&scopes[0])))
+	usageSb.WriteString("    esac\n")
+	usageSb.WriteString("}\n")
+
+	return usageSb.String()
+}
+
+// validateCLIProgram validates the program and each of its commands, every problem found is
+// returned in a ValidationErrors. The options a command inherits are taken into account but their
+// problems are only reported for the scope declaring them.
+func validateCLIProgram(cli *CLIProgram) error {
+	errs := ValidationErrors(validateProgramName(cli, programRegex))
+	errs = append(errs, validateCommands(cli, cliOptionRegex)...)
+
+	scopes := commandScopes(cli)
+	for i := range scopes {
+		scope := &scopes[i]
+		program := scopeProgram(scope)
+		firstOwnOption := len(scope.inherited)
+
+		scopeErrs := make([]*ValidationError, 0)
+		scopeErrs = append(scopeErrs, validateExistingConflictingOptionNames(&program, firstOwnOption)...)
+		scopeErrs = append(scopeErrs, validateCLIOptionNames(&scope.cli, cliOptionRegex)...)
+		scopeErrs = append(scopeErrs, validateShortOptionNamesLength(&scope.cli)...)
+		scopeErrs = append(scopeErrs, validateUniqueCLIOptionNamesCount(&program.Options, firstOwnOption)...)
+		scopeErrs = append(scopeErrs, validateOptionTypes(&scope.cli)...)
+		scopeErrs = append(scopeErrs, validateEnvironmentNames(&scope.cli, envNameRegex)...)
+		scopeErrs = append(scopeErrs, validateDefaultValues(&scope.cli)...)
+		scopeErrs = append(scopeErrs, validatePositionals(&scope.cli, cliOptionRegex)...)
+
+		for _, err := range scopeErrs {
+			err.Command = commandPath(scope)
+		}
+
+		errs = append(errs, scopeErrs...)
+	}
+
+	if len(errs) > 0 {
+		for _, err := range errs {
+			err.File = cli.fileName
+		}
+
+		return errs
+	}
+
+	return nil
+}
+
+// Validate checks the program can be generated, every problem found is returned in a
+// ValidationErrors.
+func (cli *CLIProgram) Validate() error {
+	return validateCLIProgram(cli)
+}
+
+// ValidateOption checks cliOption can be added to the options of the program: its names are valid
+// and not used yet and the options it conflicts with exist. Every problem found is returned in a
+// ValidationErrors, their Field telling which part of the option is wrong.
+func ValidateOption(cli *CLIProgram, cliOption *CLIOption) error {
+	options := make([]CLIOption, 0, len(cli.Options)+1)
+	options = append(options, cli.Options...)
+	options = append(options, *cliOption)
+
+	program := CLIProgram{Options: options}
+	own := CLIProgram{Options: []CLIOption{*cliOption}}
+
+	errs := make(ValidationErrors, 0)
+	errs = append(errs, validateCLIOptionNames(&own, cliOptionRegex)...)
+	errs = append(errs, validateShortOptionNamesLength(&own)...)
+	errs = append(errs, validateUniqueCLIOptionNamesCount(&options, len(cli.Options))...)
+	errs = append(errs, validateExistingConflictingOptionNames(&program, len(cli.Options))...)
+	errs = append(errs, validateOptionTypes(&own)...)
+	errs = append(errs, validateEnvironmentNames(&own, envNameRegex)...)
+	errs = append(errs, validateDefaultValues(&own)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ParseCLIProgram loads the program described in configFile, validates it and writes the files
+// generated for it to outputDirectory.
+func ParseCLIProgram(configFile, outputDirectory string) (CLIProgram, error) {
+	cli, err := LoadFile(configFile)
+	if err != nil {
+		return CLIProgram{}, err
+	}
+
+	if err = cli.Validate(); err != nil {
+		return CLIProgram{}, err
+	}
+
+	if _, err = GenerateDir(&cli, outputDirectory); err != nil {
+		return CLIProgram{}, &creatingOutputError{err: err}
 	}
 
 	return cli, nil
 }
+
+// creatingOutputError is an error generating the files of a program, it is ErrCreatingOutputProgram
+// and wraps the error of the generator, like ErrModifiedOutput.
+type creatingOutputError struct {
+	err error
+}
+
+func (e *creatingOutputError) Error() string {
+	return "error creating output script: " + e.err.Error()
+}
+
+func (e *creatingOutputError) Is(target error) bool {
+	return target == ErrCreatingOutputProgram
+}
+
+func (e *creatingOutputError) Unwrap() error {
+	return e.err
+}
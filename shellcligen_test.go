@@ -123,43 +123,6 @@ func Test_existInArrayName(t *testing.T) {
 	}
 }
 
-func Test_hasTheOption(t *testing.T) {
-	t.Parallel()
-
-	allOptionNames := []Name{
-		{
-			Short: "P",
-			Long:  "",
-		},
-		{
-			Long:  "extended-regexp",
-			Short: "E",
-		},
-	}
-
-	type test struct {
-		conflictsWith []string
-		hasWant       bool
-	}
-
-	tests := []test{
-		{
-			conflictsWith: []string{"P"},
-			hasWant:       true,
-		},
-		{
-			conflictsWith: []string{"X", "extended-regexp"},
-			hasWant:       true,
-		},
-	}
-
-	for _, tt := range tests {
-		if got := hasTheOption(&tt.conflictsWith, &allOptionNames); got != tt.hasWant {
-			t.Errorf("got=[%t], wants=[%t]", got, tt.hasWant)
-		}
-	}
-}
-
 func Test_validateOptionNames(t *testing.T) {
 	t.Parallel()
 
@@ -257,7 +220,7 @@ func Test_validateOptionNames(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := validateExistingConflictingOptionNames(&tt.cliProram); got != tt.wantsValidConflicts {
+		if got := len(validateExistingConflictingOptionNames(&tt.cliProram, 0)) == 0; got != tt.wantsValidConflicts {
 			t.Errorf("got=%t, wants=%t for cli option with help message `%s`", got, tt.wantsValidConflicts, tt.cliProram.Help)
 		}
 	}
@@ -266,7 +229,7 @@ func Test_validateOptionNames(t *testing.T) {
 func Test_validateCliOptionNames(t *testing.T) {
 	t.Parallel()
 
-	cliOptionRegex = regexp.MustCompile(`^[a-zA-Z_]([\-a-zA-Z0-9_]*)$`)
+	optionNameRegex := regexp.MustCompile(`^[a-zA-Z_]([\-a-zA-Z0-9_]*)$`)
 
 	type test struct {
 		cliProram CLIProgram
@@ -361,44 +324,12 @@ func Test_validateCliOptionNames(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := validateCLIOptionNames(&tt.cliProram, cliOptionRegex); got != tt.valid {
+		if got := len(validateCLIOptionNames(&tt.cliProram, optionNameRegex)) == 0; got != tt.valid {
 			t.Errorf("got=%t, wants=%t", got, tt.valid)
 		}
 	}
 }
 
-func Test_haveRepeatedElements(t *testing.T) {
-	t.Parallel()
-
-	type test struct {
-		cliOptionNamesCount *map[string]int
-		want                bool
-	}
-
-	tests := []test{
-		{
-			cliOptionNamesCount: &map[string]int{
-				"verbose": 2,
-				"help":    1,
-			},
-			want: true,
-		},
-		{
-			cliOptionNamesCount: &map[string]int{
-				"page":  1,
-				"input": 1,
-			},
-			want: false,
-		},
-	}
-
-	for _, tt := range tests {
-		if got := haveRepeatedElements(tt.cliOptionNamesCount); got != tt.want {
-			t.Errorf("got=%t, want=%t", got, tt.want)
-		}
-	}
-}
-
 func Test_validateUniqueCLIOptionNamesCount(t *testing.T) {
 	t.Parallel()
 
@@ -462,7 +393,7 @@ func Test_validateUniqueCLIOptionNamesCount(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := validateUniqueCLIOptionNamesCount(&tt.cliOptions); got != tt.want {
+		if got := len(validateUniqueCLIOptionNamesCount(&tt.cliOptions, 0)) == 0; got != tt.want {
 			t.Errorf("got=%t, want=%t", got, tt.want)
 		}
 	}
@@ -594,6 +525,17 @@ shift 2
 shift 2
 `,
 		},
+		{
+			cliOption: CLIOption{
+				ArgsRequired:  false,
+				ShortName:     "v",
+				LongName:      "verbose",
+				Required:      false,
+				ConflictsWith: []string{},
+				Help:          false,
+			},
+			want: "shift\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -624,7 +566,7 @@ func Test_generateSwitchCaseFromCLIOption(t *testing.T) {
 				ConflictsWith: []string{},
 				Help:          false,
 			},
-			want: `a)
+			want: `-a)
 a_option_flag=1
 a_arg+=("${2}")
 shift 2
@@ -640,7 +582,7 @@ shift 2
 				ConflictsWith: []string{},
 				Help:          false,
 			},
-			want: `article)
+			want: `--article)
 article_option_flag=1
 article_arg+=("${2}")
 shift 2
@@ -656,7 +598,7 @@ shift 2
 				ConflictsWith: []string{},
 				Help:          false,
 			},
-			want: `a|article)
+			want: `-a|--article)
 a_option_flag=1
 a_arg+=("${2}")
 shift 2
@@ -671,3 +613,247 @@ shift 2
 		}
 	}
 }
+
+func Test_validateShortOptionNamesLength(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliProgram CLIProgram
+		want       bool
+	}
+
+	tests := []test{
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "article"},
+					{ShortName: "", LongName: "page"},
+				},
+			},
+			want: true,
+		},
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "article", LongName: "article"},
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := len(validateShortOptionNamesLength(&tt.cliProgram)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t", got, tt.want)
+		}
+	}
+}
+
+func Test_generateGetoptCall(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliProgram CLIProgram
+		want       string
+	}
+
+	tests := []test{
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "abc", ArgsRequired: true},
+					{ShortName: "f", LongName: "flag"},
+					{ShortName: "h", LongName: "help", Help: true},
+				},
+			},
+			want: `getopt --name "$(basename "${0}")" --options 'a:fh' --long 'abc:,flag,help'`,
+		},
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "", LongName: "page", ArgsRequired: true},
+					{ShortName: "v", LongName: ""},
+				},
+			},
+			want: `getopt --name "$(basename "${0}")" --options 'v' --long 'page:'`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := generateGetoptCall(&tt.cliProgram); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateOptionFlagsInit(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true},
+			{ShortName: "", LongName: "dry-run"},
+		},
+	}
+
+	want := `a_option_flag=0
+a_arg=()
+dry_run_option_flag=0
+`
+
+	if got := generateOptionFlagsInit(&cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateScript(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		SafeFlags: false,
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true},
+		},
+	}
+
+	got := renderTestScript(t, &cliProgram)
+
+	for _, want := range []string{
+		"#!/bin/bash\n",
+		"a_option_flag=0\n",
+		`opts=$(getopt --name "$(basename "${0}")" --options 'a:' --long 'article:' -- "${@}")`,
+		"    -a|--article)\n        a_option_flag=1\n",
+		"    --)\n        shift\n        break\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated script does not contain [%s]:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "set -o errexit") {
+		t.Errorf("safe flags should not be rendered:\n%s", got)
+	}
+}
+
+func Test_conflictingOptionPairs(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliProgram CLIProgram
+		want       [][2]int
+	}
+
+	tests := []test{
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "article"},
+					{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}},
+					{ShortName: "v", LongName: "verbose"},
+				},
+			},
+			want: [][2]int{{0, 1}},
+		},
+		// Conflicts declared on both sides are reported only once.
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "article", ConflictsWith: []string{"page"}},
+					{ShortName: "p", LongName: "page", ConflictsWith: []string{"a", "p"}},
+				},
+			},
+			want: [][2]int{{0, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := conflictingOptionPairs(&tt.cliProgram)
+		if len(got) != len(tt.want) {
+			t.Fatalf("got=%v, want=%v", got, tt.want)
+		}
+
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("got=%v, want=%v", got, tt.want)
+			}
+		}
+	}
+}
+
+func Test_generateSwitchCaseFromCLIOption_help(t *testing.T) {
+	t.Parallel()
+
+	cliOption := CLIOption{
+		ShortName: "h",
+		LongName:  "help",
+		Help:      true,
+	}
+
+	want := `-h|--help)
+h_option_flag=1
+usage
+exit 0
+;;
+`
+
+	if got := generateSwitchCaseFromCLIOption(&cliOption); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_generateUsage(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Help: "Reads articles.\n",
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", Required: true, ArgsRequired: true, Description: "article to read"},
+			{ShortName: "", LongName: "page", ArgsRequired: true, ConflictsWith: []string{"a"}, Default: "1", Env: "PAGE"},
+			{ShortName: "h", LongName: "help", Help: true, Description: "show this help"},
+		},
+	}
+
+	want := `usage() {
+    echo "Usage: $(basename "${0}") [OPTIONS]"
+    cat <<'END_OF_USAGE'
+
+Reads articles.
+
+Options:
+  -a, --article <ARG>  article to read (required) (conflicts with --page)
+      --page <ARG>     (default: 1) (env: PAGE) (conflicts with -a/--article)
+  -h, --help           show this help
+
+Option values are taken from the command line first, then from their environment
+variables, then from script.conf and finally from their default values.
+END_OF_USAGE
+}
+`
+
+	if got := generateUsage(&cliProgram); got != want {
+		t.Errorf("got=[%s], want=[%s]", got, want)
+	}
+}
+
+func Test_validateEnvironmentNames(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		env  string
+		want bool
+	}
+
+	tests := []test{
+		{env: "", want: true},
+		{env: "PAGE_SIZE", want: true},
+		{env: "PAGE-SIZE", want: false},
+		{env: "1PAGE", want: false},
+	}
+
+	for _, tt := range tests {
+		cliProgram := CLIProgram{Options: []CLIOption{{LongName: "page", ArgsRequired: true, Env: tt.env}}}
+		if got := len(validateEnvironmentNames(&cliProgram, envNameRegex)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t for env `%s`", got, tt.want, tt.env)
+		}
+	}
+}
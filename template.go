@@ -0,0 +1,169 @@
+package shellcligen
+
+import (
+	// The built-in script template is embedded.
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Blocks of the built-in script template, in the order they are rendered.
+const (
+	BlockHeader   = "header"
+	BlockUsage    = "usage"
+	BlockParse    = "parse"
+	BlockValidate = "validate"
+	BlockMain     = "main"
+)
+
+// partialExtension is the extension of the files holding template partials.
+const partialExtension = ".tmpl"
+
+//go:embed templates/script.sh.tmpl
+var scriptTemplateText string
+
+var (
+	ErrUnknownTemplateBlock = errors.New("error unknown template block")
+
+	builtinScriptTemplate = template.Must(ParseScriptTemplate(scriptTemplateText))
+	commandParserTmpl     = template.Must(template.New("command_parser").Parse(commandParserTemplate))
+)
+
+// commandParserData is what the parser of a command is rendered from.
+type commandParserData struct {
+	Function, Path, Getopt, CaseArms, Commands string
+}
+
+// ScriptTemplateFuncs returns the functions script templates are parsed with. Section functions,
+// like usage or caseArms, take the program and return the code the built-in template is made of.
+// Option functions, like flagOptionName or caseArm, take an option of the program. userCode takes a
+// name and returns an empty region of user code, kept when the script is generated again.
+func ScriptTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"optionFlags":     generateOptionFlagsInit,
+		"usage":           generateUsage,
+		"typeValidators":  generateTypeValidators,
+		"configLoader":    generateConfigLoader,
+		"commandParsers":  generateCommandParsers,
+		"getopt":          generateGetoptCall,
+		"caseArms":        generateCaseArms,
+		"commandDispatch": generateRootCommandDispatch,
+		"loadConfig":      generateConfigLoaderCall,
+		"environment": func(cli *CLIProgram) string {
+			partners := conflictingPartners(cli)
+
+			return generateCommandLineFlags(cli, partners) + generateScopedSection(cli, func(scope *commandScope) string {
+				return generateEnvironment(&scope.cli, partners)
+			})
+		},
+		"defaults": func(cli *CLIProgram) string {
+			return generateScopedSection(cli, func(scope *commandScope) string {
+				return generateDefaults(&scope.cli)
+			})
+		},
+		"validation": func(cli *CLIProgram) string {
+			return generateScopedSection(cli, generateScopeValidation)
+		},
+		"positionals": func(cli *CLIProgram) string {
+			return generateScopedSection(cli, func(scope *commandScope) string {
+				return generatePositionals(&scope.cli)
+			})
+		},
+		"flagOptionName": func(cliOption CLIOption) string {
+			return flagOptionName(&cliOption)
+		},
+		"argOptionName": func(cliOption CLIOption) string {
+			return argOptionName(&cliOption)
+		},
+		"optionName": func(cliOption CLIOption) string {
+			return optionName(&cliOption)
+		},
+		"caseArm": func(cliOption CLIOption) string {
+			return generateSwitchCaseFromCLIOption(&cliOption)
+		},
+		"sanitizeOptionName": sanitizeOptionName,
+		"userCode":           userCode,
+	}
+}
+
+// ParseScriptTemplate parses a script template written with text/template and the functions of
+// ScriptTemplateFuncs. The template is executed with the *CLIProgram as dot.
+func ParseScriptTemplate(text string) (*template.Template, error) {
+	return template.New(scriptFileName).Funcs(ScriptTemplateFuncs()).Parse(text)
+}
+
+// OverrideScriptBlocks returns a copy of tmpl where each partial replaces the block it is named
+// after, like BlockHeader. Partials are parsed like script templates and every block they override
+// must be defined by tmpl.
+func OverrideScriptBlocks(tmpl *template.Template, partials map[string]string) (*template.Template, error) {
+	overridden, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == tmpl.Name() || tmpl.Lookup(name) == nil {
+			return nil, fmt.Errorf("%s: %w", name, ErrUnknownTemplateBlock)
+		}
+
+		if _, err := overridden.New(name).Parse(partials[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return overridden, nil
+}
+
+// LoadScriptPartials reads the partials of a directory, the files named after the block they
+// override with the .tmpl extension, like header.tmpl. Other files are ignored.
+func LoadScriptPartials(directory string) (map[string]string, error) {
+	if _, err := os.Stat(directory); err != nil {
+		return nil, err
+	}
+
+	fileNames, err := filepath.Glob(filepath.Join(directory, "*"+partialExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	partials := make(map[string]string, len(fileNames))
+
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading partial %s: %w", fileName, err)
+		}
+
+		partials[strings.TrimSuffix(filepath.Base(fileName), partialExtension)] = string(content)
+	}
+
+	return partials, nil
+}
+
+// BuiltinScriptTemplate returns the text of the template scripts are generated with by default,
+// custom templates can start from it.
+func BuiltinScriptTemplate() string {
+	return scriptTemplateText
+}
+
+func renderScript(tmpl *template.Template, cli *CLIProgram) (string, error) {
+	var scriptSb strings.Builder
+
+	if err := tmpl.Execute(&scriptSb, cli); err != nil {
+		return "", err
+	}
+
+	return scriptSb.String(), nil
+}
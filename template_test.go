@@ -0,0 +1,182 @@
+package shellcligen
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// renderTestScript renders the script of the program with the built-in template.
+func renderTestScript(t *testing.T, cli *CLIProgram) string {
+	t.Helper()
+
+	script, err := renderScript(builtinScriptTemplate, cli)
+	if err != nil {
+		t.Fatalf("got=%v, want no error rendering the script", err)
+	}
+
+	return script
+}
+
+func TestParseScriptTemplate(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ArgsRequired: true},
+			{ShortName: "v", LongName: "verbose"},
+		},
+	}
+
+	type test struct {
+		text string
+		want string
+	}
+
+	tests := []test{
+		{
+			text: `{{ range .Options }}{{ flagOptionName . }} {{ argOptionName . }}{{ "\n" }}{{ end }}`,
+			want: "a_option_flag a_arg\nv_option_flag v_arg\n",
+		},
+		{
+			text: `{{ sanitizeOptionName "dry-run" }} {{ optionName (index .Options 1) }}`,
+			want: "dry_run v",
+		},
+		{
+			text: `{{ caseArm (index .Options 1) }}`,
+			want: generateSwitchCaseFromCLIOption(&cliProgram.Options[1]),
+		},
+		{
+			text: "# house style\n{{ caseArms . }}",
+			want: "# house style\n" + generateCaseArms(&cliProgram),
+		},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseScriptTemplate(tt.text)
+		if err != nil {
+			t.Errorf("got=%v, want no error parsing [%s]", err, tt.text)
+
+			continue
+		}
+
+		var script bytes.Buffer
+		if err := (&Generator{ScriptTemplate: tmpl}).Generate(&cliProgram, &script); err != nil {
+			t.Errorf("got=%v, want no error rendering [%s]", err, tt.text)
+
+			continue
+		}
+
+		if got := script.String(); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+
+	if _, err := ParseScriptTemplate("{{ unknownFunc . }}"); err == nil {
+		t.Errorf("got no error, want an error for an unknown function")
+	}
+}
+
+func TestBuiltinScriptTemplate(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+
+	tmpl, err := ParseScriptTemplate(BuiltinScriptTemplate())
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	got, err := renderScript(tmpl, &cliProgram)
+	if err != nil || got != renderTestScript(t, &cliProgram) {
+		t.Errorf("got=%v, want the built-in template to render the default script", err)
+	}
+
+	if strings.Contains(got, "{{") {
+		t.Errorf("got=[%s], want every placeholder rendered", got)
+	}
+}
+
+func TestOverrideScriptBlocks(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	script := renderTestScript(t, &cliProgram)
+
+	for _, block := range []string{BlockHeader, BlockUsage, BlockParse, BlockValidate, BlockMain} {
+		if builtinScriptTemplate.Lookup(block) == nil {
+			t.Errorf("got no block %s, want it defined by the built-in template", block)
+		}
+	}
+
+	tmpl, err := OverrideScriptBlocks(builtinScriptTemplate, map[string]string{
+		BlockHeader: "#!/bin/bash\n# Copyright ACME\n",
+		BlockMain:   "\nmain \"${@}\"\n",
+	})
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	got, err := renderScript(tmpl, &cliProgram)
+	if err != nil {
+		t.Fatalf("got=%v, want no error rendering the script", err)
+	}
+
+	if !strings.HasPrefix(got, "#!/bin/bash\n# Copyright ACME\n") || !strings.HasSuffix(got, "\nmain \"${@}\"\n") {
+		t.Errorf("got=[%s], want the header and main blocks overridden", got)
+	}
+
+	if !strings.Contains(got, generateCaseArms(&cliProgram)) {
+		t.Errorf("got=[%s], want the parse block kept", got)
+	}
+
+	if renderTestScript(t, &cliProgram) != script {
+		t.Errorf("got the built-in template changed, want it left as it was")
+	}
+
+	for _, name := range []string{"footer", scriptFileName} {
+		if _, err := OverrideScriptBlocks(builtinScriptTemplate, map[string]string{name: ""}); !errors.Is(err, ErrUnknownTemplateBlock) {
+			t.Errorf("got=%v, want=%v overriding %s", err, ErrUnknownTemplateBlock, name)
+		}
+	}
+
+	if _, err := OverrideScriptBlocks(builtinScriptTemplate, map[string]string{BlockMain: "{{ unknownFunc . }}"}); err == nil {
+		t.Errorf("got no error, want an error for an unknown function")
+	}
+}
+
+func TestLoadScriptPartials(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	files := map[string]string{
+		"header.tmpl": "#!/bin/bash\n",
+		"main.tmpl":   "main\n",
+		"README":      "not a partial",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	partials, err := LoadScriptPartials(directory)
+	if err != nil {
+		t.Fatalf("got=%v, want no error", err)
+	}
+
+	want := map[string]string{BlockHeader: "#!/bin/bash\n", BlockMain: "main\n"}
+	if !reflect.DeepEqual(partials, want) {
+		t.Errorf("got=%v, want=%v", partials, want)
+	}
+
+	if _, err := LoadScriptPartials(filepath.Join(directory, "missing")); err == nil {
+		t.Errorf("got no error, want an error for a missing directory")
+	}
+}
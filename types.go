@@ -4,9 +4,71 @@ import "fmt"
 
 // CLIProgram ...
 type CLIProgram struct {
-	Help      string      `json:"message" yaml:"help_message"`
-	Options   []CLIOption `json:"options" yaml:"options"`
-	SafeFlags bool        `json:"safe_flags" yaml:"safe_flags"`
+	// Name is the file name of the script, its configuration file is named after it. script.sh is
+	// used when it is empty.
+	Name        string       `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Help        string       `json:"help_message,omitempty" yaml:"help_message,omitempty" toml:"help_message,omitempty"`
+	Options     []CLIOption  `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	Positionals []Positional `json:"positionals,omitempty" yaml:"positionals,omitempty" toml:"positionals,omitempty"`
+	Commands    []Command    `json:"commands,omitempty" yaml:"commands,omitempty" toml:"commands,omitempty"`
+	SafeFlags   bool         `json:"safe_flags,omitempty" yaml:"safe_flags,omitempty" toml:"safe_flags,omitempty"`
+
+	// fileName is the file the program was loaded from, reported along with validation errors.
+	fileName string
+
+	// namePosition is where the name of the program is declared.
+	namePosition Position
+}
+
+// Position is where something is declared in a program description, lines and columns start at 1.
+// A zero Position means the place is unknown, like for programs not loaded from YAML or JSON.
+type Position struct {
+	Line, Column int
+}
+
+func (pos Position) String() string {
+	if pos.Column == 0 {
+		return fmt.Sprintf("%d", pos.Line)
+	}
+
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// Command is a subcommand of the program, like `deploy` in `tool deploy --env prod`. Commands
+// inherit the options of the program and of their parent commands.
+type Command struct {
+	Name        string       `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Help        string       `json:"help_message,omitempty" yaml:"help_message,omitempty" toml:"help_message,omitempty"`
+	Options     []CLIOption  `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	Positionals []Positional `json:"positionals,omitempty" yaml:"positionals,omitempty" toml:"positionals,omitempty"`
+	Commands    []Command    `json:"commands,omitempty" yaml:"commands,omitempty" toml:"commands,omitempty"`
+
+	// Position of the command in the program description.
+	Position Position `json:"-" yaml:"-" toml:"-"`
+}
+
+// Positional is an argument given after the options.
+type Positional struct {
+	// Name of the argument, the generated script binds it to the <name>_positional variable.
+	Name string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+
+	// Description ...
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+
+	// Required ...
+	Required bool `json:"required,omitempty" yaml:"required,omitempty" toml:"required,omitempty"`
+
+	// Variadic positionals take any number of values and are bound to an array.
+	Variadic bool `json:"variadic,omitempty" yaml:"variadic,omitempty" toml:"variadic,omitempty"`
+
+	// MinCount is the minimum number of values of a variadic positional.
+	MinCount int `json:"min_count,omitempty" yaml:"min_count,omitempty" toml:"min_count,omitempty"`
+
+	// MaxCount is the maximum number of values of a variadic positional, 0 means no limit.
+	MaxCount int `json:"max_count,omitempty" yaml:"max_count,omitempty" toml:"max_count,omitempty"`
+
+	// Position of the positional in the program description.
+	Position Position `json:"-" yaml:"-" toml:"-"`
 }
 
 // Name ...
@@ -17,22 +79,44 @@ type Name struct {
 // CLIOption ...
 type CLIOption struct {
 	// LongName ...
-	LongName string `json:"long_name" yaml:"long_name"`
+	LongName string `json:"long_name,omitempty" yaml:"long_name,omitempty" toml:"long_name,omitempty"`
 
 	// ShortName ...
-	ShortName string `json:"short_name" yaml:"short_name"`
+	ShortName string `json:"short_name,omitempty" yaml:"short_name,omitempty" toml:"short_name,omitempty"`
 
 	// Required ...
-	Required bool `json:"required" yaml:"required"`
+	Required bool `json:"required,omitempty" yaml:"required,omitempty" toml:"required,omitempty"`
 
 	// ArgsRequired ...
-	ArgsRequired bool `json:"args_required" yaml:"args_required"`
+	ArgsRequired bool `json:"args_required,omitempty" yaml:"args_required,omitempty" toml:"args_required,omitempty"`
 
 	// ConflictsWith ...
-	ConflictsWith []string `json:"conflicts_with" yaml:"conflicts_with"`
+	ConflictsWith []string `json:"conflicts_with,omitempty" yaml:"conflicts_with,omitempty" toml:"conflicts_with,omitempty"`
 
 	// Help ...
-	Help bool `json:"is_help" yaml:"is_help"`
+	Help bool `json:"is_help,omitempty" yaml:"is_help,omitempty" toml:"is_help,omitempty"`
+
+	// Description is shown next to the option in the generated usage.
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+
+	// Type of the option argument: string, int, float, bool, enum, file, dir or path.
+	Type string `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+
+	// Choices accepted by an enum option.
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty" toml:"choices,omitempty"`
+
+	// Default value used when the option is not given by any other means.
+	Default string `json:"default,omitempty" yaml:"default,omitempty" toml:"default,omitempty"`
+
+	// Env is the environment variable the option value can be read from.
+	Env string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+
+	// Position of the option in the program description.
+	Position Position `json:"-" yaml:"-" toml:"-"`
+
+	// keyPositions are where the keys of the option are declared, validation errors about a key
+	// are reported there.
+	keyPositions map[string]Position
 }
 
 func (cliopt CLIOption) String() string {
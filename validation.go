@@ -0,0 +1,146 @@
+package shellcligen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Rules a program can break, see ValidationError.
+const (
+	RuleInvalidOptionName      = "invalid-option-name"
+	RuleShortOptionNameLength  = "short-option-name-length"
+	RuleUnknownConflict        = "unknown-conflicting-option"
+	RuleRepeatedOptionName     = "repeated-option-name"
+	RuleInvalidOptionType      = "invalid-option-type"
+	RuleInvalidEnvironmentName = "invalid-environment-name"
+	RuleInvalidDefaultValue    = "invalid-default-value"
+	RuleInvalidPositional      = "invalid-positional"
+	RuleInvalidCommand         = "invalid-command"
+	RuleInvalidProgramName     = "invalid-program-name"
+	RuleSyntax                 = "syntax"
+	RuleUnknownKey             = "unknown-key"
+)
+
+// ValidationError is a problem found loading or validating a program. It wraps the sentinel error of the
+// broken rule, like ErrInvalidOptionName, so it can be matched with errors.Is.
+type ValidationError struct {
+	// File is the program description the problem was found in, if known.
+	File string
+
+	// Position of the problem in File, zero when unknown.
+	Position Position
+
+	// Rule is the broken rule, one of the Rule constants.
+	Rule string
+
+	// Command is the path of the command the problem was found in, empty for the program itself.
+	Command string
+
+	// Option is the display name of the offending option, like -a/--article, if any.
+	Option string
+
+	// Field is the name of the offending field, as written in the program description.
+	Field string
+
+	// Message describes the problem.
+	Message string
+
+	// Err is the sentinel error of the rule.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, 5)
+
+	switch {
+	case len(e.File) > 0 && e.Position.Line > 0:
+		parts = append(parts, e.File+":"+e.Position.String())
+	case len(e.File) > 0:
+		parts = append(parts, e.File)
+	case e.Position.Line > 0:
+		parts = append(parts, e.Position.String())
+	}
+
+	if len(e.Command) > 0 {
+		parts = append(parts, "command "+e.Command)
+	}
+
+	if len(e.Option) > 0 {
+		parts = append(parts, "option "+e.Option)
+	}
+
+	if len(e.Field) > 0 {
+		parts = append(parts, e.Field)
+	}
+
+	return strings.Join(append(parts, e.Message), ": ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors holds every problem found validating a program. errors.Is matches it against
+// the sentinel error of any of its problems and errors.As gives the first *ValidationError.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Is tells whether any of the problems matches target.
+func (errs ValidationErrors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As sets target to the first problem when it is a **ValidationError.
+func (errs ValidationErrors) As(target interface{}) bool {
+	validationError, ok := target.(**ValidationError)
+	if !ok || len(errs) == 0 {
+		return false
+	}
+
+	*validationError = errs[0]
+
+	return true
+}
+
+// newOptionError returns a problem of an option, located at the key of field when it is known.
+func newOptionError(rule string, err error, cliOption *CLIOption, field, format string, args ...interface{}) *ValidationError {
+	pos, ok := cliOption.keyPositions[field]
+	if !ok {
+		pos = cliOption.Position
+	}
+
+	return &ValidationError{
+		Position: pos,
+		Rule:     rule,
+		Option:   displayOptionName(cliOption),
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+		Err:      err,
+	}
+}
+
+func newValidationError(rule string, err error, pos Position, field, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{
+		Position: pos,
+		Rule:     rule,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+		Err:      err,
+	}
+}
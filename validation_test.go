@@ -0,0 +1,205 @@
+package shellcligen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidationError_Error(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		err  ValidationError
+		want string
+	}
+
+	tests := []test{
+		{
+			err:  ValidationError{Command: "db migrate", Option: "-s/--steps", Field: "type", Message: `unknown type "bytes"`},
+			want: `command db migrate: option -s/--steps: type: unknown type "bytes"`,
+		},
+		{
+			err:  ValidationError{Field: "positionals", Message: "<files> counts can not be negative"},
+			want: "positionals: <files> counts can not be negative",
+		},
+		{
+			err:  ValidationError{File: "cli.yml", Position: Position{Line: 4, Column: 5}, Option: "-a/--all", Field: "type", Message: "invalid"},
+			want: "cli.yml:4:5: option -a/--all: type: invalid",
+		},
+		{
+			err:  ValidationError{File: "cli.toml", Field: "options.env_var", Message: "unknown key"},
+			want: "cli.toml: options.env_var: unknown key",
+		},
+		{
+			err:  ValidationError{Position: Position{Line: 2}, Message: "did not find expected key"},
+			want: "2: did not find expected key",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_validateCLIProgram(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "a", LongName: "article", ConflictsWith: []string{"x", "page"}},
+			{ShortName: "pp", LongName: "page", Type: "int"},
+		},
+		Commands: []Command{
+			{
+				Name: "list",
+				Options: []CLIOption{
+					{ShortName: "a", LongName: "all", Env: "1ALL"},
+				},
+			},
+		},
+	}
+
+	err := validateCLIProgram(&cliProgram)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got=%v, want ValidationErrors", err)
+	}
+
+	want := []ValidationError{
+		{Rule: RuleUnknownConflict, Option: "-a/--article", Field: "conflicts_with", Err: ErrParsingConflictOptions},
+		{Rule: RuleShortOptionNameLength, Option: "-pp/--page", Field: "short_name", Err: ErrInvalidOptionName},
+		{Rule: RuleInvalidOptionType, Option: "-pp/--page", Field: "type", Err: ErrInvalidOptionType},
+		{Rule: RuleRepeatedOptionName, Command: "list", Option: "-a/--all", Field: "short_name", Err: ErrRepeatedOptionNames},
+		{Rule: RuleInvalidEnvironmentName, Command: "list", Option: "-a/--all", Field: "env", Err: ErrInvalidEnvironmentName},
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("got=%v, want %d errors", errs, len(want))
+	}
+
+	for i := range want {
+		got := errs[i]
+		if got.Rule != want[i].Rule || got.Command != want[i].Command || got.Option != want[i].Option ||
+			got.Field != want[i].Field || got.Err != want[i].Err {
+			t.Errorf("got=%+v, want=%+v", *got, want[i])
+		}
+	}
+
+	for _, sentinel := range []error{ErrParsingConflictOptions, ErrInvalidOptionName, ErrInvalidOptionType, ErrRepeatedOptionNames, ErrInvalidEnvironmentName} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("got=%v, want it to match %v", err, sentinel)
+		}
+	}
+
+	if errors.Is(err, ErrInvalidPositional) {
+		t.Errorf("got=%v, want it not to match %v", err, ErrInvalidPositional)
+	}
+
+	var first *ValidationError
+	if !errors.As(err, &first) || first != errs[0] {
+		t.Errorf("got=%v, want the first problem", first)
+	}
+
+	valid := commandsTestProgram()
+	if err := validateCLIProgram(&valid); err != nil {
+		t.Errorf("got=%v, want no error", err)
+	}
+}
+
+func Test_validateCLIProgram_inheritedOptions(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{ShortName: "v", LongName: "verbose"},
+			{ShortName: "v", LongName: "version"},
+		},
+		Commands: []Command{{Name: "list"}, {Name: "show"}},
+	}
+
+	err := validateCLIProgram(&cliProgram)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Option != "-v/--version" || errs[0].Command != "" {
+		t.Errorf("got=%v, want a single repeated name problem on the program", err)
+	}
+}
+
+func Test_validatePositionals_errors(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Positionals: []Positional{
+			{Name: "source"},
+			{Name: "files", Variadic: true, MinCount: 3, MaxCount: 2},
+		},
+	}
+
+	want := []string{
+		"positionals: <files> max_count is lower than its min_count",
+		"positionals: <files> comes after the optional <source>, optional positionals must come last",
+		"positionals: the optional <source> can not be bound along with the variadic <files>",
+	}
+
+	errs := validatePositionals(&cliProgram, cliOptionRegex)
+	if len(errs) != len(want) {
+		t.Fatalf("got=%v, want=%v", errs, want)
+	}
+
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("got=[%s], want=[%s]", got, want[i])
+		}
+	}
+}
+
+func TestValidateOption(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{Options: []CLIOption{{ShortName: "a", LongName: "article"}}}
+
+	type test struct {
+		cliOption  CLIOption
+		wantFields []string
+	}
+
+	tests := []test{
+		{cliOption: CLIOption{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}}, wantFields: nil},
+		{cliOption: CLIOption{ShortName: "a", LongName: "page"}, wantFields: []string{"short_name"}},
+		{cliOption: CLIOption{ShortName: "pp", LongName: "pa-ge"}, wantFields: []string{"long_name", "short_name"}},
+		{cliOption: CLIOption{ShortName: "p", LongName: "page", ConflictsWith: []string{"x"}}, wantFields: []string{"conflicts_with"}},
+		{cliOption: CLIOption{ShortName: "p", LongName: "page", Type: "int"}, wantFields: []string{"type"}},
+	}
+
+	for _, tt := range tests {
+		err := ValidateOption(&cliProgram, &tt.cliOption)
+
+		var errs ValidationErrors
+		if tt.wantFields == nil {
+			if err != nil {
+				t.Errorf("got=%v, want no error for %s", err, tt.cliOption)
+			}
+
+			continue
+		}
+
+		if !errors.As(err, &errs) {
+			t.Errorf("got=%v, want a ValidationErrors for %s", err, tt.cliOption)
+
+			continue
+		}
+
+		fields := make([]string, 0, len(errs))
+		for _, validationError := range errs {
+			fields = append(fields, validationError.Field)
+		}
+
+		if strings.Join(fields, " ") != strings.Join(tt.wantFields, " ") {
+			t.Errorf("got=%v, want=%v for %s", fields, tt.wantFields, tt.cliOption)
+		}
+	}
+}
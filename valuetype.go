@@ -0,0 +1,234 @@
+package shellcligen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	stringType = "string"
+	intType    = "int"
+	floatType  = "float"
+	boolType   = "bool"
+	enumType   = "enum"
+	fileType   = "file"
+	dirType    = "dir"
+	pathType   = "path"
+)
+
+var (
+	intValueRegex   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatValueRegex = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+	// typeValidators holds the bash function validating each type at runtime, string values
+	// are accepted as they are.
+	typeValidators = map[string]string{
+		intType: `validate_int() {
+    if [[ ! "${2}" =~ ^[-+]?[0-9]+$ ]]; then
+        echo "invalid value for ${1}: '${2}' is not an integer" >&2
+        exit 2
+    fi
+}
+`,
+		floatType: `validate_float() {
+    if [[ ! "${2}" =~ ^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$ ]]; then
+        echo "invalid value for ${1}: '${2}' is not a number" >&2
+        exit 2
+    fi
+}
+`,
+		boolType: `validate_bool() {
+    if [[ "${2}" != "true" && "${2}" != "false" ]]; then
+        echo "invalid value for ${1}: '${2}' is not a boolean (true or false)" >&2
+        exit 2
+    fi
+}
+`,
+		enumType: `validate_enum() {
+    local option="${1}"
+    local value="${2}"
+    local choice
+
+    shift 2
+
+    for choice in "${@}"; do
+        if [[ "${value}" == "${choice}" ]]; then
+            return 0
+        fi
+    done
+
+    echo "invalid value for ${option}: '${value}' is not one of: ${*}" >&2
+    exit 2
+}
+`,
+		fileType: `validate_file() {
+    if [[ ! -f "${2}" ]]; then
+        echo "invalid value for ${1}: '${2}' is not an existing file" >&2
+        exit 2
+    fi
+}
+`,
+		dirType: `validate_dir() {
+    if [[ ! -d "${2}" ]]; then
+        echo "invalid value for ${1}: '${2}' is not an existing directory" >&2
+        exit 2
+    fi
+}
+`,
+		pathType: `validate_path() {
+    if [[ -z "${2}" ]]; then
+        echo "invalid value for ${1}: expected a path" >&2
+        exit 2
+    fi
+}
+`,
+	}
+)
+
+// optionType returns the declared type of the option, options without a type are strings.
+func optionType(cliOption *CLIOption) string {
+	typ := strings.TrimSpace(cliOption.Type)
+	if len(typ) == 0 {
+		return stringType
+	}
+
+	return typ
+}
+
+func isKnownOptionType(typ string) bool {
+	_, hasValidator := typeValidators[typ]
+
+	return typ == stringType || hasValidator
+}
+
+func validateOptionTypes(cli *CLIProgram) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	for i := range cli.Options {
+		cliOption := &cli.Options[i]
+		typ := optionType(cliOption)
+
+		switch {
+		case !isKnownOptionType(typ):
+			errs = append(errs, newOptionError(RuleInvalidOptionType, ErrInvalidOptionType, cliOption,
+				"type", "unknown type %q", cliOption.Type))
+		case typ != stringType && !cliOption.ArgsRequired:
+			errs = append(errs, newOptionError(RuleInvalidOptionType, ErrInvalidOptionType, cliOption,
+				"type", "type %q requires args_required", typ))
+		case typ == enumType && len(cliOption.Choices) == 0:
+			errs = append(errs, newOptionError(RuleInvalidOptionType, ErrInvalidOptionType, cliOption,
+				"choices", "enum options need choices"))
+		case typ != enumType && len(cliOption.Choices) > 0:
+			errs = append(errs, newOptionError(RuleInvalidOptionType, ErrInvalidOptionType, cliOption,
+				"choices", "only enum options can have choices"))
+		}
+	}
+
+	return errs
+}
+
+// isValueOfType mirrors, at generation time, the checks the generated script runs on each value.
+// The file system is not checked for file and dir values since it is not the one the script will
+// run on.
+func isValueOfType(cliOption *CLIOption, value string) bool {
+	switch optionType(cliOption) {
+	case intType:
+		return intValueRegex.MatchString(value)
+	case floatType:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return false
+		}
+
+		return floatValueRegex.MatchString(value)
+	case boolType:
+		return value == "true" || value == "false"
+	case enumType:
+		for _, choice := range cliOption.Choices {
+			if choice == value {
+				return true
+			}
+		}
+
+		return false
+	case fileType, dirType, pathType:
+		return len(value) > 0
+	default:
+		return true
+	}
+}
+
+func validateDefaultValues(cli *CLIProgram) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	for i := range cli.Options {
+		cliOption := &cli.Options[i]
+
+		switch {
+		case len(cliOption.Default) == 0:
+		case !cliOption.ArgsRequired:
+			errs = append(errs, newOptionError(RuleInvalidDefaultValue, ErrInvalidDefaultValue, cliOption,
+				"default", "only options with args_required can have a default value"))
+		case !isValueOfType(cliOption, cliOption.Default):
+			errs = append(errs, newOptionError(RuleInvalidDefaultValue, ErrInvalidDefaultValue, cliOption,
+				"default", "%q is not a valid %s value", cliOption.Default, optionType(cliOption)))
+		}
+	}
+
+	return errs
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// typeValidationCall returns the call validating the value held by valueExpression, or an empty
+// string for options which do not need to be validated.
+func typeValidationCall(cliOption *CLIOption, valueExpression string) string {
+	typ := optionType(cliOption)
+	if _, hasValidator := typeValidators[typ]; !hasValidator || !cliOption.ArgsRequired {
+		return ""
+	}
+
+	call := fmt.Sprintf(`validate_%s "%s" "%s"`, typ, displayOptionName(cliOption), valueExpression)
+
+	for _, choice := range cliOption.Choices {
+		call += " " + shellQuote(choice)
+	}
+
+	return call
+}
+
+func generateTypeValidators(cli *CLIProgram) string {
+	var validatorsSb strings.Builder
+
+	generated := make(map[string]bool)
+
+	for _, cliOption := range allScopesOptions(cli) {
+		typ := optionType(cliOption)
+
+		validator, hasValidator := typeValidators[typ]
+		if !hasValidator || generated[typ] {
+			continue
+		}
+
+		generated[typ] = true
+
+		validatorsSb.WriteString(validator)
+		validatorsSb.WriteString("\n")
+	}
+
+	return validatorsSb.String()
+}
+
+func argPlaceholder(cliOption *CLIOption) string {
+	switch typ := optionType(cliOption); typ {
+	case stringType:
+		return "<ARG>"
+	case enumType:
+		return fmt.Sprintf("<%s>", strings.Join(cliOption.Choices, "|"))
+	default:
+		return fmt.Sprintf("<%s>", strings.ToUpper(typ))
+	}
+}
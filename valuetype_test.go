@@ -0,0 +1,193 @@
+package shellcligen
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_validateOptionTypes(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      bool
+	}
+
+	tests := []test{
+		{
+			cliOption: CLIOption{LongName: "count", ArgsRequired: true, Type: "int"},
+			want:      true,
+		},
+		{
+			cliOption: CLIOption{LongName: "verbose"},
+			want:      true,
+		},
+		{
+			cliOption: CLIOption{LongName: "count", ArgsRequired: true, Type: "integer"},
+			want:      false,
+		},
+		// Typed options must take an argument.
+		{
+			cliOption: CLIOption{LongName: "count", Type: "int"},
+			want:      false,
+		},
+		{
+			cliOption: CLIOption{LongName: "format", ArgsRequired: true, Type: "enum"},
+			want:      false,
+		},
+		{
+			cliOption: CLIOption{LongName: "format", ArgsRequired: true, Choices: []string{"json"}},
+			want:      false,
+		},
+		{
+			cliOption: CLIOption{LongName: "format", ArgsRequired: true, Type: "enum", Choices: []string{"json"}},
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		cliProgram := CLIProgram{Options: []CLIOption{tt.cliOption}}
+		if got := len(validateOptionTypes(&cliProgram)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t for option %+v", got, tt.want, tt.cliOption)
+		}
+	}
+}
+
+func Test_isValueOfType(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		value     string
+		want      bool
+	}
+
+	tests := []test{
+		{cliOption: CLIOption{Type: "int"}, value: "-42", want: true},
+		{cliOption: CLIOption{Type: "int"}, value: "abc", want: false},
+		{cliOption: CLIOption{Type: "int"}, value: "4.2", want: false},
+		{cliOption: CLIOption{Type: "float"}, value: "4.2", want: true},
+		{cliOption: CLIOption{Type: "float"}, value: "1e3", want: true},
+		{cliOption: CLIOption{Type: "float"}, value: "NaN", want: false},
+		{cliOption: CLIOption{Type: "bool"}, value: "true", want: true},
+		{cliOption: CLIOption{Type: "bool"}, value: "yes", want: false},
+		{cliOption: CLIOption{Type: "enum", Choices: []string{"json", "yaml"}}, value: "yaml", want: true},
+		{cliOption: CLIOption{Type: "enum", Choices: []string{"json", "yaml"}}, value: "toml", want: false},
+		{cliOption: CLIOption{Type: "dir"}, value: "/tmp", want: true},
+		{cliOption: CLIOption{}, value: "anything", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isValueOfType(&tt.cliOption, tt.value); got != tt.want {
+			t.Errorf("got=%t, want=%t for value `%s` of type `%s`", got, tt.want, tt.value, tt.cliOption.Type)
+		}
+	}
+}
+
+func Test_validateDefaultValues(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliProgram CLIProgram
+		want       bool
+	}
+
+	tests := []test{
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{LongName: "count", ArgsRequired: true, Type: "int", Default: "10"},
+					{LongName: "name", ArgsRequired: true},
+				},
+			},
+			want: true,
+		},
+		{
+			cliProgram: CLIProgram{
+				Options: []CLIOption{
+					{LongName: "count", ArgsRequired: true, Type: "int", Default: "ten"},
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := len(validateDefaultValues(&tt.cliProgram)) == 0; got != tt.want {
+			t.Errorf("got=%t, want=%t", got, tt.want)
+		}
+	}
+}
+
+func Test_typeValidationCall(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{
+			cliOption: CLIOption{ShortName: "c", LongName: "count", ArgsRequired: true, Type: "int"},
+			want:      `validate_int "-c/--count" "${2}"`,
+		},
+		{
+			cliOption: CLIOption{LongName: "format", ArgsRequired: true, Type: "enum", Choices: []string{"json", "it's"}},
+			want:      `validate_enum "--format" "${2}" 'json' 'it'\''s'`,
+		},
+		{
+			cliOption: CLIOption{LongName: "name", ArgsRequired: true},
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := typeValidationCall(&tt.cliOption, "${2}"); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateTypeValidators(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := CLIProgram{
+		Options: []CLIOption{
+			{LongName: "count", ArgsRequired: true, Type: "int"},
+			{LongName: "retries", ArgsRequired: true, Type: "int"},
+			{LongName: "name", ArgsRequired: true},
+		},
+	}
+
+	got := generateTypeValidators(&cliProgram)
+
+	if strings.Count(got, "validate_int() {") != 1 {
+		t.Errorf("validate_int should be generated once:\n%s", got)
+	}
+
+	if strings.Contains(got, "validate_enum") {
+		t.Errorf("validate_enum should not be generated:\n%s", got)
+	}
+}
+
+func Test_argPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		cliOption CLIOption
+		want      string
+	}
+
+	tests := []test{
+		{cliOption: CLIOption{}, want: "<ARG>"},
+		{cliOption: CLIOption{Type: "int"}, want: "<INT>"},
+		{cliOption: CLIOption{Type: "enum", Choices: []string{"json", "yaml"}}, want: "<json|yaml>"},
+	}
+
+	for _, tt := range tests {
+		if got := argPlaceholder(&tt.cliOption); got != tt.want {
+			t.Errorf("got=%s, want=%s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package shellcligen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zshCompletionFileName returns the completion file name, named after the whole script name like
+// its #compdef line so it does not shadow the completion of another command, like _script.
+func zshCompletionFileName(cli *CLIProgram) string {
+	return "_" + scriptName(cli)
+}
+
+func zshQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func zshEscapeDescription(description string) string {
+	replacer := strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+	return replacer.Replace(strings.TrimSpace(firstHelpLine(description)))
+}
+
+func zshArgumentAction(cliOption *CLIOption) string {
+	switch optionType(cliOption) {
+	case enumType:
+		return fmt.Sprintf("(%s)", strings.Join(cliOption.Choices, " "))
+	case boolType:
+		return "(true false)"
+	case fileType, pathType:
+		return "_files"
+	case dirType:
+		return "_files -/"
+	default:
+		return ""
+	}
+}
+
+// zshOptionSpecs returns the _arguments specs of the option, the exclusion list holds the options
+// it conflicts with so zsh stops offering them once one of them is on the command line.
+func zshOptionSpecs(program *CLIProgram, optionIndex int) []string {
+	cliOption := &program.Options[optionIndex]
+	exclusions := make([]string, 0)
+
+	if !cliOption.ArgsRequired {
+		exclusions = append(exclusions, optionNames(cliOption)...)
+	}
+
+	for _, pair := range conflictingOptionPairs(program) {
+		switch optionIndex {
+		case pair[0]:
+			exclusions = append(exclusions, optionNames(&program.Options[pair[1]])...)
+		case pair[1]:
+			exclusions = append(exclusions, optionNames(&program.Options[pair[0]])...)
+		}
+	}
+
+	exclusionList := ""
+	if len(exclusions) > 0 {
+		exclusionList = fmt.Sprintf("(%s)", strings.Join(exclusions, " "))
+	}
+
+	// Options with arguments can be given more than once.
+	if cliOption.ArgsRequired {
+		exclusionList += "*"
+	}
+
+	argument := ""
+	if cliOption.ArgsRequired {
+		argument = fmt.Sprintf(":%s:%s", configKeyName(cliOption), zshArgumentAction(cliOption))
+	}
+
+	specs := make([]string, 0, 2)
+
+	for _, name := range optionNames(cliOption) {
+		if cliOption.ArgsRequired && strings.HasPrefix(name, "--") {
+			name += "="
+		}
+
+		specs = append(specs, zshQuote(fmt.Sprintf("%s%s[%s]%s",
+			exclusionList, name, zshEscapeDescription(cliOption.Description), argument)))
+	}
+
+	return specs
+}
+
+func zshPositionalSpec(positional *Positional) string {
+	name := strings.TrimSpace(positional.Name)
+
+	switch {
+	case positional.Variadic:
+		return zshQuote(fmt.Sprintf("*:%s:_files", name))
+	case positional.Required:
+		return zshQuote(fmt.Sprintf(":%s:_files", name))
+	default:
+		return zshQuote(fmt.Sprintf("::%s:_files", name))
+	}
+}
+
+func zshFunctionName(scope *commandScope) string {
+	if len(scope.path) == 0 {
+		return "_" + nonIdentifierRegex.ReplaceAllString(scriptName(&scope.cli), "_")
+	}
+
+	return fmt.Sprintf("_%s__%s", nonIdentifierRegex.ReplaceAllString(scriptName(&scope.cli), "_"),
+		commandFunctionSuffix(scope))
+}
+
+func generateZshScopeFunction(scope *commandScope) string {
+	var functionSb strings.Builder
+
+	program := scopeProgram(scope)
+	specs := make([]string, 0)
+
+	for i := range program.Options {
+		specs = append(specs, zshOptionSpecs(&program, i)...)
+	}
+
+	for i := range scope.cli.Positionals {
+		specs = append(specs, zshPositionalSpec(&scope.cli.Positionals[i]))
+	}
+
+	functionSb.WriteString(fmt.Sprintf("%s() {\n", zshFunctionName(scope)))
+
+	if len(scope.cli.Commands) == 0 {
+		functionSb.WriteString("    _arguments -s -S")
+
+		for _, spec := range specs {
+			functionSb.WriteString(" \\\n        " + spec)
+		}
+
+		functionSb.WriteString("\n}\n")
+
+		return functionSb.String()
+	}
+
+	functionSb.WriteString("    local context state state_descr line\n")
+	functionSb.WriteString("    typeset -A opt_args\n\n")
+	functionSb.WriteString("    _arguments -s -S -C")
+
+	for _, spec := range specs {
+		functionSb.WriteString(" \\\n        " + spec)
+	}
+
+	functionSb.WriteString(" \\\n        '1: :->command' \\\n        '*:: :->args'\n\n")
+	functionSb.WriteString("    case \"${state}\" in\n    command)\n        local -a commands\n        commands=(\n")
+
+	for _, command := range scope.cli.Commands {
+		functionSb.WriteString(fmt.Sprintf("            %s\n",
+			zshQuote(fmt.Sprintf("%s:%s", strings.TrimSpace(command.Name), zshEscapeDescription(command.Help)))))
+	}
+
+	functionSb.WriteString("        )\n        _describe -t commands 'command' commands\n        ;;\n")
+	functionSb.WriteString("    args)\n        case \"${words[1]}\" in\n")
+
+	for _, command := range scope.cli.Commands {
+		name := strings.TrimSpace(command.Name)
+		child := commandScope{path: append(append([]string{}, scope.path...), name), cli: CLIProgram{Name: scope.cli.Name}}
+
+		functionSb.WriteString(fmt.Sprintf("        %s)\n            %s\n            ;;\n", name, zshFunctionName(&child)))
+	}
+
+	functionSb.WriteString("        esac\n        ;;\n    esac\n}\n")
+
+	return functionSb.String()
+}
+
+// generateZshCompletion generates a zsh completion function, one function per command is generated
+// and the program one dispatches to them.
+func generateZshCompletion(cli *CLIProgram) string {
+	var completionSb strings.Builder
+
+	completionSb.WriteString(fmt.Sprintf("#compdef %s\n\n", scriptName(cli)))
+	completionSb.WriteString(fmt.Sprintf("# Zsh completion for %s, copy it to a directory in your fpath.\n\n", scriptName(cli)))
+
+	scopes := commandScopes(cli)
+	for i := len(scopes) - 1; i >= 0; i-- {
+		completionSb.WriteString(generateZshScopeFunction(&scopes[i]))
+		completionSb.WriteString("\n")
+	}
+
+	completionSb.WriteString(fmt.Sprintf("%s \"${@}\"\n", zshFunctionName(&scopes[0])))
+
+	return completionSb.String()
+}
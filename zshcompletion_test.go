@@ -0,0 +1,117 @@
+package shellcligen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_zshOptionSpecs(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		program     CLIProgram
+		optionIndex int
+		want        string
+	}
+
+	tests := []test{
+		{
+			program: CLIProgram{Options: []CLIOption{
+				{ShortName: "v", LongName: "verbose", Description: "be chatty"},
+			}},
+			optionIndex: 0,
+			want:        `'(-v --verbose)-v[be chatty]' '(-v --verbose)--verbose[be chatty]'`,
+		},
+		{
+			program: CLIProgram{Options: []CLIOption{
+				{ShortName: "a", LongName: "article"},
+				{ShortName: "p", LongName: "page", ConflictsWith: []string{"a"}},
+			}},
+			optionIndex: 0,
+			want:        `'(-a --article -p --page)-a[]' '(-a --article -p --page)--article[]'`,
+		},
+		{
+			program: CLIProgram{Options: []CLIOption{
+				{ShortName: "f", LongName: "format", ArgsRequired: true, Type: "enum", Choices: []string{"json", "yaml"}},
+			}},
+			optionIndex: 0,
+			want:        `'*-f[]:format:(json yaml)' '*--format=[]:format:(json yaml)'`,
+		},
+		{
+			program: CLIProgram{Options: []CLIOption{
+				{ShortName: "o", ArgsRequired: true, Type: "file", Description: "output [file]"},
+			}},
+			optionIndex: 0,
+			want:        `'*-o[output \[file\]]:o:_files'`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(zshOptionSpecs(&tt.program, tt.optionIndex), " "); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_zshPositionalSpec(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		positional Positional
+		want       string
+	}
+
+	tests := []test{
+		{positional: Positional{Name: "target", Required: true}, want: `':target:_files'`},
+		{positional: Positional{Name: "target"}, want: `'::target:_files'`},
+		{positional: Positional{Name: "files", Variadic: true}, want: `'*:files:_files'`},
+	}
+
+	for _, tt := range tests {
+		if got := zshPositionalSpec(&tt.positional); got != tt.want {
+			t.Errorf("got=[%s], want=[%s]", got, tt.want)
+		}
+	}
+}
+
+func Test_generateZshCompletion(t *testing.T) {
+	t.Parallel()
+
+	cliProgram := commandsTestProgram()
+	got := generateZshCompletion(&cliProgram)
+
+	for _, want := range []string{
+		"#compdef script.sh\n",
+		"_script_sh__db__migrate() {\n    _arguments -s -S \\\n",
+		"            'deploy:deploy the application'\n",
+		"        migrate)\n            _script_sh__db__migrate\n            ;;\n",
+		"        ':target:_files'\n}\n",
+		"_script_sh \"${@}\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("zsh completion does not contain [%s]:\n%s", want, got)
+		}
+	}
+
+	cliProgram.Name = "deploy-tool"
+	got = generateZshCompletion(&cliProgram)
+
+	defined := regexp.MustCompile(`(?m)^(_[a-z_]+)\(\) \{$`).FindAllStringSubmatch(got, -1)
+	called := regexp.MustCompile(`(?m)^ {12}(_[a-z_]+)$`).FindAllStringSubmatch(got, -1)
+
+	functions := make(map[string]bool)
+	for _, match := range defined {
+		functions[match[1]] = true
+	}
+
+	if len(called) != 3 || !functions["_deploy_tool__db__migrate"] {
+		t.Errorf("got=%v, want the functions of deploy-tool dispatched to", called)
+	}
+
+	for _, match := range called {
+		if !functions[match[1]] {
+			t.Errorf("got a call to %s, want one of the defined functions %v", match[1], functions)
+		}
+	}
+}